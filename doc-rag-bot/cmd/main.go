@@ -2,45 +2,187 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
+	"doc-rag-bot/pkg/api"
 	"doc-rag-bot/pkg/llm"
+	"doc-rag-bot/pkg/notes"
 	"doc-rag-bot/pkg/rag"
 )
 
+// controllerStateJSON mirrors the JSON shape of kube-controller-viz's
+// parser.ControllerState -- specifically the RecentSteps field its
+// /api/state endpoint serves -- decoded here rather than imported directly
+// since this repo keeps doc-rag-bot and kube-controller-viz as independent
+// sub-projects with no shared module.
+type controllerStateJSON struct {
+	RecentSteps []struct {
+		ReconcileID   string `json:"reconcileId"`
+		Controller    string `json:"controller"`
+		ControllerKnd string `json:"controllerKind"`
+		Namespace     string `json:"namespace"`
+		Name          string `json:"name"`
+		StepType      string `json:"stepType"`
+		Description   string `json:"description"`
+		Timestamp     int64  `json:"timestamp"`
+		Duration      int64  `json:"duration"`
+		Status        string `json:"status"`
+	} `json:"recentSteps"`
+}
+
+// ingestReconcileState fetches kube-controller-viz's controller state from
+// stateURL (its /api/state endpoint), groups RecentSteps by ReconcileID via
+// rag.GroupReconcileSteps, and indexes each reconcile as a Document via
+// rag.Indexer.IngestReconcileSteps.
+func ingestReconcileState(indexer *rag.Indexer, stateURL string) error {
+	resp, err := http.Get(stateURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch controller state from %s: %w", stateURL, err)
+	}
+	defer resp.Body.Close()
+
+	var state controllerStateJSON
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return fmt.Errorf("failed to decode controller state from %s: %w", stateURL, err)
+	}
+
+	steps := make([]rag.ReconcileStepTrace, len(state.RecentSteps))
+	for i, s := range state.RecentSteps {
+		steps[i] = rag.ReconcileStepTrace{
+			ReconcileID:    s.ReconcileID,
+			Controller:     s.Controller,
+			ControllerKind: s.ControllerKnd,
+			Namespace:      s.Namespace,
+			Name:           s.Name,
+			StepType:       s.StepType,
+			Description:    s.Description,
+			Timestamp:      s.Timestamp,
+			Duration:       s.Duration,
+			Status:         s.Status,
+		}
+	}
+
+	return indexer.IngestReconcileSteps(steps)
+}
+
 func main() {
 	// Define command-line flags
 	docsDir := flag.String("docs", "./docs", "Directory containing documents to index")
 	ollamaURL := flag.String("ollama-url", "http://localhost:11434", "Ollama API URL")
 	embeddingModel := flag.String("embedding-model", "llama3", "Ollama model to use for embeddings")
-	llmModel := flag.String("llm-model", "qwen2.5", "Ollama model to use for generation")
+	llmBackend := flag.String("llm-backend", "ollama", "LLM backend to use for generation (ollama, openai, anthropic, local)")
+	llmModel := flag.String("llm-model", "qwen2.5", "Model to use for generation")
+	llmBaseURL := flag.String("llm-base-url", "", "Base URL of the LLM backend. Defaults to --ollama-url for the ollama backend and to https://api.anthropic.com for anthropic; required for openai.")
+	llmAPIKey := flag.String("llm-api-key", "", "API key for the LLM backend, required by openai/anthropic.")
+	llmLocalBinary := flag.String("llm-local-binary", "", "Path to a llama.cpp CLI binary, used only when --llm-backend=local.")
+	llmLocalModel := flag.String("llm-local-model-path", "", "Path to an on-disk GGUF model, used only when --llm-backend=local.")
+	apiPort := flag.Int("api-port", 0, "Port to additionally serve /api/analyze/stream on. Leave 0 to disable.")
+	semanticChunking := flag.Bool("semantic-chunking", false, "Chunk documents by merging semantically similar adjacent paragraphs instead of packing by token budget alone.")
+	semanticSimilarityThreshold := flag.Float64("semantic-similarity-threshold", 0.75, "Minimum cosine similarity between adjacent paragraphs to merge them into one chunk. Only used with --semantic-chunking.")
+	retrieveK := flag.Int("retrieve-k", 7, "Number of candidate chunks to retrieve before optional reranking.")
+	rerank := flag.Bool("rerank", false, "Rerank retrieved chunks with the LLM before generating an answer.")
+	rerankModel := flag.String("rerank-model", "qwen2.5", "Model to use for reranking, if --rerank is set.")
+	rerankN := flag.Int("rerank-n", 3, "Number of top-ranked chunks to keep after reranking, if --rerank is set.")
+	indexPath := flag.String("index-path", "", "Path to persist the indexed documents/embeddings to, so restarts only re-embed changed files. Leave empty to always index from scratch.")
+	reconcileStateURL := flag.String("reconcile-state-url", "", "URL of a kube-controller-viz /api/state endpoint to ingest reconcile traces from, one Document per ReconcileID. Leave empty to skip.")
+	notesK := flag.Int("notes-k", 3, "Number of past shift notes (see the 'note' command) to retrieve as extra RAG context per query. 0 disables notes retrieval.")
 	flag.Parse()
 
-	// Create Ollama client
-	ollamaClient := llm.NewOllamaClient(*ollamaURL, *llmModel)
+	baseURL := *llmBaseURL
+	if baseURL == "" && (*llmBackend == "" || *llmBackend == string(llm.GeneratorOllama)) {
+		baseURL = *ollamaURL
+	}
+
+	// Create the LLM generator for the selected backend
+	generator, err := llm.NewGenerator(llm.GeneratorConfig{
+		Adapter:         llm.GeneratorAdapter(*llmBackend),
+		BaseURL:         baseURL,
+		Model:           *llmModel,
+		APIKey:          *llmAPIKey,
+		LocalBinaryPath: *llmLocalBinary,
+		LocalModelPath:  *llmLocalModel,
+	})
+	if err != nil {
+		fmt.Printf("Error creating llm generator: %v\n", err)
+		os.Exit(1)
+	}
+
+	// notesStore holds free-text shift notes (see the "note" command),
+	// retrieved alongside document chunks and included in the LLM prompt as
+	// extra RAG context.
+	notesStore := notes.NewStore(llm.NewOllamaClient(*ollamaURL, *embeddingModel).EmbeddingFunc(*embeddingModel))
 
 	// Create indexer and index documents
+	var indexer *rag.Indexer
+	if *semanticChunking {
+		indexer = rag.NewIndexerWithSemanticChunking(*ollamaURL, *embeddingModel, *semanticSimilarityThreshold)
+	} else {
+		indexer = rag.NewIndexer(*ollamaURL, *embeddingModel)
+	}
+
+	if *indexPath != "" {
+		if err := indexer.Load(*indexPath); err != nil {
+			fmt.Printf("Error loading persisted index: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	fmt.Println("Indexing documents from", *docsDir)
-	indexer := rag.NewIndexer(*ollamaURL, *embeddingModel)
-	err := indexer.IndexDirectory(*docsDir)
+	err = indexer.IndexDirectory(*docsDir)
 	if err != nil {
 		fmt.Printf("Error indexing documents: %v\n", err)
 		os.Exit(1)
 	}
 	fmt.Println("Indexing complete!")
 
+	if *reconcileStateURL != "" {
+		fmt.Println("Ingesting reconcile traces from", *reconcileStateURL)
+		if err := ingestReconcileState(indexer, *reconcileStateURL); err != nil {
+			fmt.Printf("Error ingesting reconcile traces: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Reconcile trace ingestion complete!")
+	}
+
+	if *indexPath != "" {
+		if err := indexer.Save(*indexPath); err != nil {
+			fmt.Printf("Error persisting index: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Create retriever
 	retriever := rag.NewRetriever(indexer)
 
+	var reranker *rag.Reranker
+	if *rerank {
+		reranker = rag.NewReranker(*ollamaURL, *rerankModel, *rerankN)
+	}
+
+	if *apiPort != 0 {
+		server := api.NewServer(retriever, generator)
+		go func() {
+			fmt.Printf("Serving /api/analyze/stream on :%d\n", *apiPort)
+			if err := server.Start(*apiPort); err != nil {
+				fmt.Printf("API server stopped: %v\n", err)
+			}
+		}()
+	}
+
 	// Main interaction loop
 	scanner := bufio.NewScanner(os.Stdin)
 	fmt.Println("\nSpecial commands:")
 	fmt.Println("  exit - Exit the program")
 	fmt.Println("  list - List all indexed documents")
 	fmt.Println("  show <filename> - Show the content of a document")
+	fmt.Println("  reindex - Force re-embedding of every document in --docs, ignoring the persisted index")
+	fmt.Println("  note <text> - Save a free-text shift note, retrieved as extra context for later queries")
 	fmt.Println("\nDoc RAG Bot ready! Type your questions or commands:")
 	for {
 		fmt.Print("\n> ")
@@ -66,6 +208,31 @@ func main() {
 			continue
 		}
 
+		if query == "reindex" {
+			fmt.Println("Reindexing documents from", *docsDir)
+			if err := indexer.Reindex(*docsDir); err != nil {
+				fmt.Printf("Error reindexing documents: %v\n", err)
+				continue
+			}
+			if *indexPath != "" {
+				if err := indexer.Save(*indexPath); err != nil {
+					fmt.Printf("Error persisting index: %v\n", err)
+				}
+			}
+			fmt.Println("Reindexing complete!")
+			continue
+		}
+
+		if strings.HasPrefix(query, "note ") {
+			text := strings.TrimPrefix(query, "note ")
+			if err := notesStore.Add(context.Background(), text); err != nil {
+				fmt.Printf("Error saving note: %v\n", err)
+			} else {
+				fmt.Println("Note saved.")
+			}
+			continue
+		}
+
 		if strings.HasPrefix(query, "show ") {
 			docName := strings.TrimPrefix(query, "show ")
 			if doc, ok := indexer.Documents[docName]; ok {
@@ -81,22 +248,47 @@ func main() {
 
 		// Retrieve relevant document chunks
 		fmt.Println("Retrieving relevant information...")
-		chunks, err := retriever.RetrieveRelevantChunks(query, 7) // Get top 5 relevant chunks
+		chunks, err := retriever.RetrieveRelevantChunks(query, *retrieveK)
 		if err != nil {
 			fmt.Printf("Error retrieving information: %v\n", err)
 			continue
 		}
 
-		// Generate response with RAG
+		if reranker != nil {
+			fmt.Println("Reranking candidates...")
+			chunks, err = reranker.Rerank(query, chunks)
+			if err != nil {
+				fmt.Printf("Error reranking information: %v\n", err)
+				continue
+			}
+		}
+
+		if *notesK > 0 {
+			noteResults, err := notesStore.Search(context.Background(), query, *notesK)
+			if err != nil {
+				fmt.Printf("Error retrieving notes: %v\n", err)
+			} else {
+				for _, r := range noteResults {
+					chunks = append(chunks, r.Text)
+				}
+			}
+		}
+
+		// Generate response with RAG, printing tokens as they arrive
 		fmt.Println("Generating response...")
-		response, err := ollamaClient.GenerateWithContext(query, chunks)
+		fmt.Println("\n=== Response ===")
+		_, err = generator.GenerateWithContextStream(query, chunks, func(token string) error {
+			fmt.Print(token)
+			return nil
+		})
+		fmt.Println()
 		if err != nil {
 			fmt.Printf("Error generating response: %v\n", err)
 			continue
 		}
 
 		// Add suggestion about creating domain-specific content
-		enhancedResponse := response + "\n\n---\n" +
+		tip := "\n---\n" +
 			"💡 **Tip**: For better results, add domain-specific knowledge by creating a text file:\n" +
 			"```bash\n" +
 			"cat > docs/domain-guide.txt << 'EOF'\n" +
@@ -111,8 +303,7 @@ func main() {
 			"```\n" +
 			"Then run `make run` to index the new content."
 
-		fmt.Println("\n=== Response ===")
-		fmt.Println(enhancedResponse)
+		fmt.Println(tip)
 		fmt.Println("================")
 	}
 
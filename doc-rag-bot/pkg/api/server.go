@@ -0,0 +1,90 @@
+// Package api exposes doc-rag-bot's retrieval-and-generation pipeline over
+// HTTP, so a frontend can render progressive answers instead of only
+// driving the REPL.
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"doc-rag-bot/pkg/llm"
+	"doc-rag-bot/pkg/rag"
+)
+
+// Server represents the API server
+type Server struct {
+	retriever *rag.Retriever
+	llmClient llm.Generator
+}
+
+// NewServer creates a new API server
+func NewServer(retriever *rag.Retriever, llmClient llm.Generator) *Server {
+	return &Server{
+		retriever: retriever,
+		llmClient: llmClient,
+	}
+}
+
+// Start starts the API server on the specified port
+func (s *Server) Start(port int) error {
+	http.HandleFunc("/api/analyze/stream", s.handleAnalyzeStream)
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+}
+
+// handleAnalyzeStream retrieves relevant chunks for ?q= and streams the LLM's
+// answer back as Server-Sent Events, one "data:" line per token, so a
+// frontend can render the answer progressively instead of waiting for the
+// full 2000-token generation to finish.
+func (s *Server) handleAnalyzeStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "missing required query parameter: q", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	chunks, err := s.retriever.RetrieveRelevantChunks(query, 7)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retrieve context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	_, err = s.llmClient.GenerateWithContextStream(query, chunks, func(token string) error {
+		fmt.Fprintf(w, "data: %s\n\n", escapeSSE(token))
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", escapeSSE(err.Error()))
+		flusher.Flush()
+		return
+	}
+
+	fmt.Fprint(w, "event: done\ndata: {}\n\n")
+	flusher.Flush()
+}
+
+// escapeSSE keeps a token from breaking the "data: ...\n\n" framing by
+// replacing embedded newlines, since SSE treats a bare newline as the end
+// of a field.
+func escapeSSE(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '\n' {
+			out = append(out, '\\', 'n')
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
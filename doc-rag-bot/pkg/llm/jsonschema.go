@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Schema is a minimal JSON Schema subset -- just enough to validate the
+// structured objects an LLM is asked to emit (object/array/string/number
+// /integer/boolean, required properties, nested objects and arrays). There's
+// no go.mod in this repo to pin a full JSON Schema library to, and this
+// subset covers what GenerateJSON needs: catching a model's malformed or
+// incomplete output before it reaches the caller's struct.
+type Schema struct {
+	Type       string            `json:"type,omitempty"`
+	Properties map[string]Schema `json:"properties,omitempty"`
+	Required   []string          `json:"required,omitempty"`
+	Items      *Schema           `json:"items,omitempty"`
+}
+
+// validate checks value (the result of unmarshaling into interface{})
+// against s, returning every violation found rather than stopping at the
+// first, so a self-repair reprompt can describe the whole problem at once.
+func (s Schema) validate(path string, value interface{}) []string {
+	if s.Type == "" {
+		return nil
+	}
+
+	if !typeMatches(s.Type, value) {
+		return []string{fmt.Sprintf("%s: expected type %q, got %s", path, s.Type, describeType(value))}
+	}
+
+	var violations []string
+
+	switch s.Type {
+	case "object":
+		obj, _ := value.(map[string]interface{})
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %q", path, name))
+			}
+		}
+
+		// Iterate properties in sorted order so violation messages (and
+		// any test against them) are deterministic.
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propValue, ok := obj[name]
+			if !ok {
+				continue
+			}
+			violations = append(violations, s.Properties[name].validate(path+"."+name, propValue)...)
+		}
+
+	case "array":
+		if s.Items != nil {
+			items, _ := value.([]interface{})
+			for i, item := range items {
+				violations = append(violations, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	}
+
+	return violations
+}
+
+// typeMatches reports whether value's dynamic type (as produced by
+// encoding/json unmarshaling into interface{}) satisfies schema type t.
+func typeMatches(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	default:
+		return true
+	}
+}
+
+func describeType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -2,35 +2,81 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// defaultVersion is the fallback shift-week version reported in the
+// User-Agent header when the binary wasn't built with a version string.
+const defaultVersion = "dev"
+
 // OllamaClient represents a client for the Ollama API
 type OllamaClient struct {
 	baseURL    string
 	model      string
 	httpClient *http.Client
+	maxRetries int
+	userAgent  string
+}
+
+// Option configures an OllamaClient, for use with NewOllamaClient.
+type Option func(*OllamaClient)
+
+// WithTimeout sets the overall timeout for a single HTTP request (one
+// retry attempt), including connection and body read. The default is 60s.
+func WithTimeout(d time.Duration) Option {
+	return func(c *OllamaClient) { c.httpClient.Timeout = d }
+}
+
+// WithRetry sets how many times a request is retried after a 5xx/429
+// response or a transient network error, with exponential backoff between
+// attempts. The default is 3.
+func WithRetry(maxRetries int) Option {
+	return func(c *OllamaClient) { c.maxRetries = maxRetries }
+}
+
+// WithUserAgent overrides the default "shift-week/<version>" User-Agent
+// sent with every request, so operators of a shared Ollama instance can
+// attribute traffic to something more specific than the default.
+func WithUserAgent(userAgent string) Option {
+	return func(c *OllamaClient) { c.userAgent = userAgent }
 }
 
 // NewOllamaClient creates a new Ollama client
-func NewOllamaClient(baseURL, model string) *OllamaClient {
-	return &OllamaClient{
+func NewOllamaClient(baseURL, model string, opts ...Option) *OllamaClient {
+	c := &OllamaClient{
 		baseURL:    baseURL,
 		model:      model,
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		maxRetries: 3,
+		userAgent:  "shift-week/" + defaultVersion,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // Request represents the request to the Ollama API
 type Request struct {
-	Model   string  `json:"model"`
-	Prompt  string  `json:"prompt"`
-	Stream  bool    `json:"stream"`
-	Options Options `json:"options,omitempty"`
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	// Images are additional images to pass to a vision model (e.g. llava)
+	// alongside Prompt. encoding/json marshals each []byte element as a
+	// base64 string, which is exactly the wire format Ollama's "images"
+	// field expects, so no manual encoding step is needed here.
+	Images  [][]byte `json:"images,omitempty"`
+	Options Options  `json:"options,omitempty"`
+	// Format, when set to "json", puts Ollama into constrained-decoding
+	// mode so the model's output is guaranteed to be syntactically valid
+	// JSON. Set by GenerateJSON; other callers leave it empty.
+	Format string `json:"format,omitempty"`
 }
 
 // Options represents the options for the Ollama API
@@ -40,20 +86,139 @@ type Options struct {
 	MaxTokens   int     `json:"max_tokens,omitempty"`
 }
 
-// Response represents the response from the Ollama API
+// Response represents one newline-delimited JSON chunk Ollama emits from
+// /api/generate. Done and the eval fields below are only populated on the
+// final chunk of a streamed response.
 type Response struct {
-	Model     string `json:"model"`
-	Response  string `json:"response"`
-	CreatedAt string `json:"created_at"`
+	Model              string `json:"model"`
+	Response           string `json:"response"`
+	CreatedAt          string `json:"created_at"`
+	Done               bool   `json:"done"`
+	TotalDuration      int64  `json:"total_duration,omitempty"`
+	LoadDuration       int64  `json:"load_duration,omitempty"`
+	PromptEvalCount    int    `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64  `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int    `json:"eval_count,omitempty"`
+	EvalDuration       int64  `json:"eval_duration,omitempty"`
 }
 
-// GenerateWithContext sends a query with document context to the LLM
-func (c *OllamaClient) GenerateWithContext(query string, contexts []string) (string, error) {
-	// Combine contexts into a single string
+// EmbeddingFunc embeds text into a vector, abstracting callers (e.g. a
+// vector store over past entries) away from the concrete embedding
+// backend. OllamaClient.Embed, bound to a model, satisfies this type.
+type EmbeddingFunc func(ctx context.Context, text string) ([]float32, error)
+
+// embeddingRequest and embeddingResponse mirror Ollama's /api/embeddings
+// request/response bodies. They're distinct from pkg/rag's
+// EmbeddingRequest/EmbeddingResponse, which shape the same endpoint for
+// that package's own Embedder implementation.
+type embeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type embeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// do sends body to url, retrying on 5xx/429 responses and transient
+// network errors with exponential backoff, and returns the response once a
+// non-retryable status is reached. The caller is responsible for closing
+// the returned response's Body. ctx governs request cancellation, both
+// within a single attempt and across the retry loop's backoff sleeps.
+func (c *OllamaClient) do(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff(attempt)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("request failed: %w", err)
+			continue
+		}
+
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(respBody))
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", c.maxRetries+1, lastErr)
+}
+
+// backoff returns the delay before retry attempt (1-based), doubling with
+// each attempt from a short 250ms start: 250ms, 500ms, 1s, ...
+func backoff(attempt int) time.Duration {
+	return time.Duration(1<<uint(attempt-1)) * 250 * time.Millisecond
+}
+
+// doAndDecode posts body to url via do, validates the final status, and
+// decodes the JSON response body into out.
+func (c *OllamaClient) doAndDecode(ctx context.Context, url string, body []byte, out interface{}) error {
+	resp, err := c.do(ctx, url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Embed sends prompt to Ollama's /api/embeddings endpoint using model (e.g.
+// "nomic-embed-text") and returns the resulting vector. Unlike Generate, it
+// takes model explicitly rather than using c.model, since a client
+// generating with one model commonly embeds with a smaller, dedicated one.
+func (c *OllamaClient) Embed(ctx context.Context, model, prompt string) ([]float32, error) {
+	reqJSON, err := json.Marshal(embeddingRequest{Model: model, Prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var response embeddingResponse
+	if err := c.doAndDecode(ctx, c.baseURL+"/api/embeddings", reqJSON, &response); err != nil {
+		return nil, err
+	}
+	return response.Embedding, nil
+}
+
+// EmbeddingFunc returns an EmbeddingFunc bound to model, for passing to
+// callers (e.g. pkg/notes.Store) that only know about the functional type.
+func (c *OllamaClient) EmbeddingFunc(model string) EmbeddingFunc {
+	return func(ctx context.Context, text string) ([]float32, error) {
+		return c.Embed(ctx, model, text)
+	}
+}
+
+// ragPrompt builds the RAG prompt shared by GenerateWithContext and
+// GenerateWithContextStream.
+func ragPrompt(query string, contexts []string) string {
 	contextText := strings.Join(contexts, "\n\n")
 
-	// Create the prompt with improved RAG format
-	prompt := fmt.Sprintf(`You are an expert technical assistant specializing in Kubernetes, OpenShift, and HyperShift. 
+	return fmt.Sprintf(`You are an expert technical assistant specializing in Kubernetes, OpenShift, and HyperShift.
 Answer the following question using ONLY the information provided in the context below.
 
 CONTEXT:
@@ -67,6 +232,11 @@ INSTRUCTIONS:
 3. Be specific, technical, and detailed in your response.
 4. If the context contains relevant troubleshooting steps, include them in a clear, step-by-step format.
 5. Format your response using markdown for readability.`, contextText, query)
+}
+
+// GenerateWithContext sends a query with document context to the LLM
+func (c *OllamaClient) GenerateWithContext(query string, contexts []string) (string, error) {
+	prompt := ragPrompt(query, contexts)
 
 	reqBody := Request{
 		Model:  c.model,
@@ -83,9 +253,230 @@ INSTRUCTIONS:
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(c.baseURL+"/api/generate", "application/json", bytes.NewBuffer(reqJSON))
+	var response Response
+	if err := c.doAndDecode(context.Background(), c.baseURL+"/api/generate", reqJSON, &response); err != nil {
+		return "", err
+	}
+	return response.Response, nil
+}
+
+// Generate sends a raw, non-streaming prompt straight to the LLM without
+// wrapping it in the RAG context-and-instructions template, for callers
+// (like Reranker) that build their own prompt.
+func (c *OllamaClient) Generate(prompt string) (string, error) {
+	reqBody := Request{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: false,
+	}
+
+	reqJSON, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request to Ollama: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var response Response
+	if err := c.doAndDecode(context.Background(), c.baseURL+"/api/generate", reqJSON, &response); err != nil {
+		return "", err
+	}
+	return response.Response, nil
+}
+
+// visionModelSubstrings names known vision-capable Ollama models. It's a
+// best-effort allowlist -- Ollama has no API to ask a model whether it
+// accepts images -- so GenerateWithImages only uses it to fail fast with a
+// clear error; an unrecognized-but-valid vision model still reaches Ollama,
+// which will surface its own error if it can't handle images.
+var visionModelSubstrings = []string{"llava", "bakllava", "moondream", "vision"}
+
+// supportsImages reports whether model looks like a known vision-capable
+// model, by substring match against visionModelSubstrings.
+func supportsImages(model string) bool {
+	lower := strings.ToLower(model)
+	for _, substr := range visionModelSubstrings {
+		if strings.Contains(lower, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateWithImages behaves like Generate, but attaches images (e.g.
+// screenshots of dashboards or incident graphs) to the prompt for a vision
+// model such as llava to caption or reason about. It rejects models not
+// recognized as vision-capable before sending the request, so a caller gets
+// a clear client-side error instead of an opaque one from Ollama.
+func (c *OllamaClient) GenerateWithImages(prompt string, images [][]byte) (string, error) {
+	if !supportsImages(c.model) {
+		return "", fmt.Errorf("model %q is not a recognized vision model (expected one of: %s)", c.model, strings.Join(visionModelSubstrings, ", "))
+	}
+
+	reqBody := Request{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: false,
+		Images: images,
+	}
+
+	reqJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var response Response
+	if err := c.doAndDecode(context.Background(), c.baseURL+"/api/generate", reqJSON, &response); err != nil {
+		return "", err
+	}
+	return response.Response, nil
+}
+
+// maxJSONRepairAttempts bounds how many times GenerateJSON reprompts the
+// model after a schema validation failure, so a model that can't produce
+// valid output fails loudly instead of looping forever.
+const maxJSONRepairAttempts = 2
+
+// GenerateJSON sends prompt to Ollama with "format": "json" (Ollama's
+// constrained-decoding mode, which guarantees syntactically valid JSON)
+// and unmarshals the result into out. If schema is non-nil, the decoded
+// value is also validated against it; on a violation, GenerateJSON
+// reprompts the model up to maxJSONRepairAttempts times, feeding back the
+// violations so it can correct its own output, before giving up. This is
+// for extracting structured data (e.g. an incident summary's owner,
+// severity, and links) reliably instead of parsing free-form prose.
+func (c *OllamaClient) GenerateJSON(ctx context.Context, prompt string, schema *Schema, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		reqJSON, err := json.Marshal(Request{
+			Model:  c.model,
+			Prompt: prompt,
+			Stream: false,
+			Format: "json",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		var response Response
+		if err := c.doAndDecode(ctx, c.baseURL+"/api/generate", reqJSON, &response); err != nil {
+			return err
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(response.Response), &decoded); err != nil {
+			return fmt.Errorf("model returned invalid JSON: %w (raw: %s)", err, response.Response)
+		}
+
+		var violations []string
+		if schema != nil {
+			violations = schema.validate("$", decoded)
+		}
+
+		if len(violations) == 0 {
+			if err := json.Unmarshal([]byte(response.Response), out); err != nil {
+				return fmt.Errorf("failed to unmarshal model response into %T: %w", out, err)
+			}
+			return nil
+		}
+
+		if attempt >= maxJSONRepairAttempts {
+			return fmt.Errorf("model output failed schema validation after %d attempts: %v", attempt+1, violations)
+		}
+
+		prompt = repairPrompt(prompt, response.Response, violations)
+	}
+}
+
+// repairPrompt builds a follow-up prompt asking the model to fix its own
+// output against the schema violations found in it.
+func repairPrompt(originalPrompt, badOutput string, violations []string) string {
+	return fmt.Sprintf(`%s
+
+Your previous response was:
+%s
+
+That response does not satisfy the required schema:
+%s
+
+Respond again with ONLY corrected JSON that satisfies the schema.`, originalPrompt, badOutput, strings.Join(violations, "\n"))
+}
+
+// GenerateStream sends req to /api/generate with Stream forced to true and
+// invokes onChunk with each token as Ollama emits it, without buffering the
+// full response the way Generate/GenerateWithContext do. Unlike
+// GenerateWithContextStream, it takes a caller-built Request directly
+// (rather than wrapping a query/contexts pair in the RAG prompt template)
+// and honors ctx: canceling ctx aborts the underlying HTTP request
+// mid-stream instead of waiting for Ollama to finish generating. Retries
+// only cover the initial connection, not a stream that's already begun.
+func (c *OllamaClient) GenerateStream(ctx context.Context, req Request, onChunk func(chunk string) error) error {
+	req.Model = c.model
+	req.Stream = true
+
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.baseURL+"/api/generate", reqJSON)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk Response
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to decode streamed response: %w", err)
+		}
+
+		if chunk.Response != "" {
+			if err := onChunk(chunk.Response); err != nil {
+				return err
+			}
+		}
+
+		if chunk.Done {
+			return nil
+		}
+	}
+}
+
+// GenerateWithContextStream behaves like GenerateWithContext, but streams
+// the response from Ollama token by token: onToken is invoked with each
+// token as it arrives, and the full accumulated response is returned once
+// generation completes. This lets callers (the REPL, an SSE endpoint)
+// render progressive output instead of blocking on the full generation.
+// Retries only cover the initial connection, not a stream that's already
+// begun.
+func (c *OllamaClient) GenerateWithContextStream(query string, contexts []string, onToken func(string) error) (string, error) {
+	prompt := ragPrompt(query, contexts)
+
+	reqBody := Request{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: true,
+		Options: Options{
+			Temperature: 0.3,
+			MaxTokens:   2000,
+		},
+	}
+
+	reqJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.do(context.Background(), c.baseURL+"/api/generate", reqJSON)
+	if err != nil {
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -94,10 +485,30 @@ INSTRUCTIONS:
 		return "", fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	var response Response
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	var full strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk Response
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return full.String(), fmt.Errorf("failed to decode streamed response: %w", err)
+		}
+
+		if chunk.Response != "" {
+			full.WriteString(chunk.Response)
+			if onToken != nil {
+				if err := onToken(chunk.Response); err != nil {
+					return full.String(), err
+				}
+			}
+		}
+
+		if chunk.Done {
+			break
+		}
 	}
 
-	return response.Response, nil
+	return full.String(), nil
 }
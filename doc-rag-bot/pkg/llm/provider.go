@@ -0,0 +1,237 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Generator answers a RAG query given retrieved context chunks, abstracting
+// the REPL and API server from the concrete LLM backend. OllamaClient already
+// satisfies this via its existing GenerateWithContext/GenerateWithContextStream
+// methods.
+type Generator interface {
+	GenerateWithContext(query string, contexts []string) (string, error)
+	GenerateWithContextStream(query string, contexts []string, onToken func(string) error) (string, error)
+}
+
+// GeneratorAdapter identifies a supported LLM generation backend.
+type GeneratorAdapter string
+
+const (
+	GeneratorOllama    GeneratorAdapter = "ollama"
+	GeneratorOpenAI    GeneratorAdapter = "openai"
+	GeneratorAnthropic GeneratorAdapter = "anthropic"
+	GeneratorLocal     GeneratorAdapter = "local"
+)
+
+// GeneratorConfig selects and configures a Generator.
+type GeneratorConfig struct {
+	Adapter GeneratorAdapter
+	BaseURL string
+	Model   string
+	APIKey  string
+
+	// LocalBinaryPath and LocalModelPath configure the local adapter, which
+	// shells out to a llama.cpp CLI binary against an on-disk GGUF model
+	// instead of calling an HTTP backend. Used only when Adapter == GeneratorLocal.
+	LocalBinaryPath string
+	LocalModelPath  string
+}
+
+// NewGenerator builds the Generator selected by cfg.Adapter.
+func NewGenerator(cfg GeneratorConfig) (Generator, error) {
+	switch cfg.Adapter {
+	case "", GeneratorOllama:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("ollama generator requires BaseURL")
+		}
+		return NewOllamaClient(cfg.BaseURL, cfg.Model), nil
+	case GeneratorOpenAI:
+		if cfg.BaseURL == "" || cfg.APIKey == "" {
+			return nil, fmt.Errorf("openai generator requires BaseURL and APIKey")
+		}
+		return &openAIGenerator{cfg: cfg, httpClient: &http.Client{}}, nil
+	case GeneratorAnthropic:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("anthropic generator requires APIKey")
+		}
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = "https://api.anthropic.com"
+		}
+		return &anthropicGenerator{cfg: cfg, httpClient: &http.Client{}}, nil
+	case GeneratorLocal:
+		if cfg.LocalBinaryPath == "" || cfg.LocalModelPath == "" {
+			return nil, fmt.Errorf("local generator requires LocalBinaryPath and LocalModelPath")
+		}
+		return &localGenerator{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown llm generator adapter %q", cfg.Adapter)
+	}
+}
+
+// streamOnce is the fallback GenerateWithContextStream for backends that
+// can't stream natively: it runs the full generation, then invokes onToken
+// once with the complete answer so callers get a single consistent API
+// regardless of backend.
+func streamOnce(generate func() (string, error), onToken func(string) error) (string, error) {
+	full, err := generate()
+	if err != nil {
+		return full, err
+	}
+	if onToken != nil {
+		if err := onToken(full); err != nil {
+			return full, err
+		}
+	}
+	return full, nil
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIGenerator talks to any OpenAI-compatible /v1/chat/completions
+// endpoint. It does not support token streaming, so GenerateWithContextStream
+// falls back to a single onToken call via streamOnce.
+type openAIGenerator struct {
+	cfg        GeneratorConfig
+	httpClient *http.Client
+}
+
+func (g *openAIGenerator) GenerateWithContext(query string, contexts []string) (string, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model": g.cfg.Model,
+		"messages": []openAIChatMessage{
+			{Role: "user", Content: ragPrompt(query, contexts)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.cfg.BaseURL+"/v1/chat/completions", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.cfg.APIKey)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to OpenAI-compatible API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("OpenAI-compatible API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message openAIChatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("openai response had no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
+
+func (g *openAIGenerator) GenerateWithContextStream(query string, contexts []string, onToken func(string) error) (string, error) {
+	return streamOnce(func() (string, error) { return g.GenerateWithContext(query, contexts) }, onToken)
+}
+
+// anthropicGenerator talks to Anthropic's /v1/messages API. It does not
+// support token streaming, so GenerateWithContextStream falls back to a
+// single onToken call via streamOnce.
+type anthropicGenerator struct {
+	cfg        GeneratorConfig
+	httpClient *http.Client
+}
+
+func (g *anthropicGenerator) GenerateWithContext(query string, contexts []string) (string, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model":      g.cfg.Model,
+		"max_tokens": 2000,
+		"messages": []openAIChatMessage{
+			{Role: "user", Content: ragPrompt(query, contexts)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.cfg.BaseURL+"/v1/messages", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", g.cfg.APIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to Anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("Anthropic API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var parsed struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Content) == 0 {
+		return "", fmt.Errorf("anthropic response had no content")
+	}
+	return parsed.Content[0].Text, nil
+}
+
+func (g *anthropicGenerator) GenerateWithContextStream(query string, contexts []string, onToken func(string) error) (string, error) {
+	return streamOnce(func() (string, error) { return g.GenerateWithContext(query, contexts) }, onToken)
+}
+
+// localGenerator runs a llama.cpp CLI binary as a subprocess against an
+// on-disk GGUF model, for air-gapped deployments where no HTTP backend is
+// reachable. It does not support token streaming, so
+// GenerateWithContextStream falls back to a single onToken call via
+// streamOnce.
+type localGenerator struct {
+	cfg GeneratorConfig
+}
+
+func (g *localGenerator) GenerateWithContext(query string, contexts []string) (string, error) {
+	prompt := ragPrompt(query, contexts)
+
+	cmd := exec.Command(g.cfg.LocalBinaryPath, "-m", g.cfg.LocalModelPath, "-p", prompt, "--no-display-prompt")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("llama.cpp invocation failed: %w (stderr: %s)", err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (g *localGenerator) GenerateWithContextStream(query string, contexts []string, onToken func(string) error) (string, error) {
+	return streamOnce(func() (string, error) { return g.GenerateWithContext(query, contexts) }, onToken)
+}
@@ -0,0 +1,99 @@
+// Package logging provides a small structured logger for the indexer and
+// retriever, emitting the same field schema kube-controller-viz's
+// parser.LogEntry already understands (level, ts, msg, controller,
+// namespace, name, reconcileID), plus an optional Loki push sink so this
+// tool's own trace can land in the same Loki instance the visualizer
+// queries.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fields are the structured key/values attached to a log line. Only the
+// keys that map onto the shared schema (namespace, name, reconcileID) are
+// captured as dedicated fields; anything else is folded into the message.
+type Fields map[string]interface{}
+
+type entry struct {
+	Level       string `json:"level"`
+	Timestamp   string `json:"ts"`
+	Message     string `json:"msg"`
+	Component   string `json:"controller"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	ReconcileID string `json:"reconcileID,omitempty"`
+}
+
+// Sink receives one already-encoded JSON log line at a time.
+type Sink interface {
+	Write(line []byte)
+}
+
+// Logger is a minimal structured logger for a single component (e.g.
+// "indexer", "retriever"). It always writes to stdout and, if a Sink was
+// configured, mirrors each line there too.
+type Logger struct {
+	component string
+	sink      Sink
+}
+
+// NewLogger creates a Logger for component, optionally mirroring every line
+// to sink (e.g. a LokiSink). Pass a nil sink to log only to stdout.
+func NewLogger(component string, sink Sink) *Logger {
+	return &Logger{component: component, sink: sink}
+}
+
+// Info logs an informational message with optional structured fields.
+func (l *Logger) Info(msg string, fields Fields) {
+	l.write("info", msg, fields)
+}
+
+// Error logs an error alongside msg and optional structured fields.
+func (l *Logger) Error(err error, msg string, fields Fields) {
+	l.write("error", fmt.Sprintf("%s: %v", msg, err), fields)
+}
+
+func (l *Logger) write(level, msg string, fields Fields) {
+	e := entry{
+		Level:     level,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Message:   msg,
+		Component: l.component,
+	}
+	if v, ok := fields["namespace"].(string); ok {
+		e.Namespace = v
+	}
+	if v, ok := fields["name"].(string); ok {
+		e.Name = v
+	}
+	if v, ok := fields["reconcileID"].(string); ok {
+		e.ReconcileID = v
+	}
+	var extra []string
+	for k, v := range fields {
+		if k == "namespace" || k == "name" || k == "reconcileID" {
+			continue
+		}
+		extra = append(extra, fmt.Sprintf("%s=%v", k, v))
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		e.Message += " " + strings.Join(extra, " ")
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stdout, string(line))
+	if l.sink != nil {
+		l.sink.Write(line)
+	}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LokiSink pushes each log line to Loki's push API under a fixed set of
+// stream labels.
+type LokiSink struct {
+	url    string
+	labels map[string]string
+	client *http.Client
+}
+
+// NewLokiSink creates a LokiSink that pushes to url (Loki's
+// /loki/api/v1/push endpoint) under the given stream labels.
+func NewLokiSink(url string, labels map[string]string) *LokiSink {
+	return &LokiSink{
+		url:    url,
+		labels: labels,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Write pushes line to Loki. Errors are swallowed, so a failed push never
+// fails the caller that's logging. The push is synchronous: each call waits
+// for Loki, up to the client's 5s timeout.
+func (s *LokiSink) Write(line []byte) {
+	push := lokiPushRequest{
+		Streams: []lokiStream{{
+			Stream: s.labels,
+			Values: [][2]string{{
+				strconv.FormatInt(time.Now().UnixNano(), 10),
+				string(line),
+			}},
+		}},
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+}
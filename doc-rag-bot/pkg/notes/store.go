@@ -0,0 +1,102 @@
+// Package notes is a minimal in-memory vector store over free-text entries
+// (e.g. past shift notes), embedded through a pluggable
+// llm.EmbeddingFunc so the concrete embedding backend is swappable, with
+// cosine-similarity search for finding entries similar to a new query --
+// for including similar past entries as RAG context in a prompt.
+package notes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"doc-rag-bot/pkg/llm"
+)
+
+// Entry is one stored note and the vector it was embedded to.
+type Entry struct {
+	Text      string
+	Embedding []float32
+}
+
+// Result is an Entry returned from Search, alongside its similarity to the
+// query.
+type Result struct {
+	Entry
+	Score float32
+}
+
+// Store embeds and holds Entries in memory, with no persistence of its own
+// -- callers that need notes to survive a restart are expected to reload
+// them (e.g. replaying Add for each past entry) on startup.
+type Store struct {
+	embed llm.EmbeddingFunc
+
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// NewStore creates a Store that embeds new entries and queries via embed.
+func NewStore(embed llm.EmbeddingFunc) *Store {
+	return &Store{embed: embed}
+}
+
+// Add embeds text and appends it to the store.
+func (s *Store) Add(ctx context.Context, text string) error {
+	embedding, err := s.embed(ctx, text)
+	if err != nil {
+		return fmt.Errorf("failed to embed note: %w", err)
+	}
+
+	s.mu.Lock()
+	s.entries = append(s.entries, Entry{Text: text, Embedding: embedding})
+	s.mu.Unlock()
+	return nil
+}
+
+// Search embeds query and returns the topK stored entries most similar to
+// it by cosine similarity, most similar first.
+func (s *Store) Search(ctx context.Context, query string, topK int) ([]Result, error) {
+	queryEmbedding, err := s.embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	s.mu.RLock()
+	results := make([]Result, len(s.entries))
+	for i, entry := range s.entries {
+		results[i] = Result{Entry: entry, Score: cosineSimilarity(queryEmbedding, entry.Embedding)}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is empty or a zero vector.
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) == 0 || len(b) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,188 @@
+package rag
+
+import (
+	"math"
+	"strings"
+)
+
+// BM25 scoring constants. k1 controls term-frequency saturation, b controls
+// how much document length is normalized against the corpus average.
+const (
+	bm25K1 = 1.5
+	bm25B  = 0.75
+)
+
+// bm25Doc holds the per-chunk statistics BM25 needs: term frequencies within
+// the chunk and its total token count.
+type bm25Doc struct {
+	chunk     *Chunk
+	termFreq  map[string]int
+	docLength int
+}
+
+// BM25Index is a keyword index over the indexed chunks, owned by Indexer
+// (see Indexer.BM25), which builds it lazily and keeps it current through
+// addChunk/removeChunk. It scores queries using the standard Okapi
+// BM25 formula with corpus-derived document frequencies and average document
+// length, rather than the fixed placeholder constants a naive implementation
+// would use.
+type BM25Index struct {
+	docs         []bm25Doc
+	docFreq      map[string]int // number of chunks each term appears in
+	avgDocLength float64
+	numDocs      int
+}
+
+// NewBM25Index builds a BM25Index over chunks, tokenizing each chunk's
+// content with the same preprocessText pipeline used before embedding so
+// keyword matching stays consistent with the dense index.
+func NewBM25Index(chunks []*Chunk) *BM25Index {
+	idx := &BM25Index{
+		docFreq: make(map[string]int),
+		numDocs: len(chunks),
+	}
+
+	var totalLength int
+	for _, chunk := range chunks {
+		terms := tokenize(chunk.Content)
+		termFreq := make(map[string]int, len(terms))
+		for _, term := range terms {
+			termFreq[term]++
+		}
+		for term := range termFreq {
+			idx.docFreq[term]++
+		}
+
+		idx.docs = append(idx.docs, bm25Doc{
+			chunk:     chunk,
+			termFreq:  termFreq,
+			docLength: len(terms),
+		})
+		totalLength += len(terms)
+	}
+
+	if idx.numDocs > 0 {
+		idx.avgDocLength = float64(totalLength) / float64(idx.numDocs)
+	}
+
+	return idx
+}
+
+// newBM25IndexFromStats rebuilds a BM25Index's per-chunk term frequencies
+// (cheap local tokenization, no corpus scan) while reusing already-computed
+// corpus-level stats -- docFreq, avgDocLength, numDocs -- instead of
+// recomputing them, for restoring a BM25Index from a persisted snapshot
+// without rescoring the whole corpus.
+func newBM25IndexFromStats(chunks []*Chunk, docFreq map[string]int, avgDocLength float64, numDocs int) *BM25Index {
+	idx := &BM25Index{
+		docFreq:      docFreq,
+		avgDocLength: avgDocLength,
+		numDocs:      numDocs,
+	}
+
+	for _, chunk := range chunks {
+		terms := tokenize(chunk.Content)
+		termFreq := make(map[string]int, len(terms))
+		for _, term := range terms {
+			termFreq[term]++
+		}
+		idx.docs = append(idx.docs, bm25Doc{
+			chunk:     chunk,
+			termFreq:  termFreq,
+			docLength: len(terms),
+		})
+	}
+
+	return idx
+}
+
+// addChunk incrementally folds chunk's term statistics into the index --
+// O(len(chunk.Content)) tokenization, no corpus rescan -- updating docFreq,
+// avgDocLength, and numDocs in place. Used by Indexer.registerDocument so
+// AddDocument/UpdateDocument don't pay NewBM25Index's full-corpus cost for
+// a single new document.
+func (idx *BM25Index) addChunk(chunk *Chunk) {
+	terms := tokenize(chunk.Content)
+	termFreq := make(map[string]int, len(terms))
+	for _, term := range terms {
+		termFreq[term]++
+	}
+	for term := range termFreq {
+		idx.docFreq[term]++
+	}
+
+	totalLength := idx.avgDocLength*float64(idx.numDocs) + float64(len(terms))
+	idx.numDocs++
+	idx.avgDocLength = totalLength / float64(idx.numDocs)
+
+	idx.docs = append(idx.docs, bm25Doc{
+		chunk:     chunk,
+		termFreq:  termFreq,
+		docLength: len(terms),
+	})
+}
+
+// removeChunk reverses addChunk for the chunk with the given ID, if it's
+// present in the index. Used by Indexer.removeDocumentByID.
+func (idx *BM25Index) removeChunk(chunkID string) {
+	for pos, doc := range idx.docs {
+		if doc.chunk.ID != chunkID {
+			continue
+		}
+
+		for term := range doc.termFreq {
+			idx.docFreq[term]--
+			if idx.docFreq[term] <= 0 {
+				delete(idx.docFreq, term)
+			}
+		}
+
+		totalLength := idx.avgDocLength*float64(idx.numDocs) - float64(doc.docLength)
+		idx.numDocs--
+		if idx.numDocs > 0 {
+			idx.avgDocLength = totalLength / float64(idx.numDocs)
+		} else {
+			idx.avgDocLength = 0
+		}
+
+		idx.docs = append(idx.docs[:pos], idx.docs[pos+1:]...)
+		return
+	}
+}
+
+// tokenize splits preprocessed text on whitespace into terms.
+func tokenize(text string) []string {
+	return strings.Fields(preprocessText(text))
+}
+
+// idf computes the BM25 inverse document frequency for a term, using the
+// standard smoothed formula so even a term appearing in every document still
+// contributes a small positive weight.
+func (idx *BM25Index) idf(term string) float64 {
+	df := float64(idx.docFreq[term])
+	n := float64(idx.numDocs)
+	return math.Log((n-df+0.5)/(df+0.5) + 1)
+}
+
+// Score returns each chunk's BM25 score against query, in the same order as
+// the index's underlying chunks.
+func (idx *BM25Index) Score(query string) []ChunkScore {
+	queryTerms := tokenize(query)
+
+	scores := make([]ChunkScore, len(idx.docs))
+	for i, doc := range idx.docs {
+		var score float64
+		for _, term := range queryTerms {
+			freq, ok := doc.termFreq[term]
+			if !ok {
+				continue
+			}
+			numerator := float64(freq) * (bm25K1 + 1)
+			denominator := float64(freq) + bm25K1*(1-bm25B+bm25B*(float64(doc.docLength)/idx.avgDocLength))
+			score += idx.idf(term) * (numerator / denominator)
+		}
+		scores[i] = ChunkScore{Chunk: doc.chunk, Score: float32(score)}
+	}
+
+	return scores
+}
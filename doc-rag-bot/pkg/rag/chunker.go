@@ -0,0 +1,245 @@
+package rag
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// charsPerToken is a tiktoken-style BPE token estimate: roughly 4 characters
+// per token for English prose. It's a placeholder for a real per-model
+// tokenizer, good enough to keep chunks within an embedding model's token
+// limit without pulling in a BPE dependency.
+const charsPerToken = 4
+
+func estimateTokens(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+	n := len(s) / charsPerToken
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+// segment is one atomic or sentence-level unit of a document, with its byte
+// offsets in the original content so chunks built from it can report a
+// source span.
+type segment struct {
+	text   string
+	start  int
+	end    int
+	atomic bool // fenced code block or YAML block scalar: never split further
+}
+
+var fencedBlockRegex = regexp.MustCompile("(?s)```.*?```")
+
+// yamlBlockScalarRegex matches a "key: |" or "key: >" line followed by its
+// indented body, so the body isn't shredded by sentence splitting.
+var yamlBlockScalarRegex = regexp.MustCompile(`(?m)^([ \t]*)\S[^\n]*:\s*[|>][+-]?[ \t]*\n(?:(?:[ \t]*\n)|(?:\x20{2,}\S[^\n]*\n)|(?:\t+\S[^\n]*\n))*`)
+
+// abbreviations are words that precede a "." without ending a sentence.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true,
+	"inc": true, "ltd": true, "co": true, "corp": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true,
+	"jr": true, "sr": true, "st": true, "no": true,
+}
+
+// findAtomicSpans returns the sorted, non-overlapping byte ranges of content
+// that must be kept intact: fenced code blocks and YAML block scalars.
+func findAtomicSpans(content string) [][2]int {
+	var spans [][2]int
+	for _, re := range []*regexp.Regexp{fencedBlockRegex, yamlBlockScalarRegex} {
+		for _, m := range re.FindAllStringIndex(content, -1) {
+			spans = append(spans, [2]int{m[0], m[1]})
+		}
+	}
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	merged := [][2]int{spans[0]}
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s[0] <= last[1] {
+			if s[1] > last[1] {
+				last[1] = s[1]
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// segmentDocument splits content into an ordered list of segments: atomic
+// spans (fenced code, YAML block scalars) verbatim, and sentence-level
+// segments everywhere else.
+func segmentDocument(content string) []segment {
+	atomicSpans := findAtomicSpans(content)
+
+	var segments []segment
+	cursor := 0
+	for _, span := range atomicSpans {
+		if span[0] > cursor {
+			segments = append(segments, sentenceSegments(content, cursor, span[0])...)
+		}
+		segments = append(segments, segment{
+			text:   content[span[0]:span[1]],
+			start:  span[0],
+			end:    span[1],
+			atomic: true,
+		})
+		cursor = span[1]
+	}
+	if cursor < len(content) {
+		segments = append(segments, sentenceSegments(content, cursor, len(content))...)
+	}
+
+	return segments
+}
+
+// sentenceSegments splits content[start:end] into sentence-level segments,
+// respecting common abbreviations so "e.g. this" isn't split mid-thought.
+func sentenceSegments(content string, start, end int) []segment {
+	text := content[start:end]
+
+	var segments []segment
+	sentenceStart := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '.' && c != '!' && c != '?' {
+			continue
+		}
+		// Require the terminator to be followed by whitespace (or EOF) to
+		// count as a sentence boundary.
+		if i+1 < len(text) && !isSpace(text[i+1]) {
+			continue
+		}
+		if isAbbreviation(text[sentenceStart:i]) {
+			continue
+		}
+
+		sentence := strings.TrimSpace(text[sentenceStart : i+1])
+		if sentence != "" {
+			offset := strings.Index(text[sentenceStart:], sentence)
+			segStart := start + sentenceStart + offset
+			segments = append(segments, segment{
+				text:  sentence,
+				start: segStart,
+				end:   segStart + len(sentence),
+			})
+		}
+		sentenceStart = i + 1
+	}
+
+	if tail := strings.TrimSpace(text[sentenceStart:]); tail != "" {
+		offset := strings.Index(text[sentenceStart:], tail)
+		segStart := start + sentenceStart + offset
+		segments = append(segments, segment{
+			text:  tail,
+			start: segStart,
+			end:   segStart + len(tail),
+		})
+	}
+
+	return segments
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// isAbbreviation reports whether the text immediately preceding a "."
+// terminator is a known abbreviation rather than the end of a sentence.
+func isAbbreviation(precedingText string) bool {
+	fields := strings.Fields(precedingText)
+	if len(fields) == 0 {
+		return false
+	}
+	last := strings.ToLower(strings.Trim(fields[len(fields)-1], "."))
+	return abbreviations[last]
+}
+
+// packSegments greedily packs segments into chunks bounded by tokenBudget,
+// carrying a sliding overlap of roughly overlapTokens from the end of each
+// chunk into the start of the next so retrieval doesn't lose context at
+// chunk boundaries. Atomic segments are never split, even if a single one
+// exceeds tokenBudget on its own.
+func packSegments(segments []segment, docID string, tokenBudget, overlapTokens int) []Chunk {
+	var chunks []Chunk
+	var current []segment
+	currentTokens := 0
+	chunkCount := 0
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		chunks = append(chunks, buildChunk(current, docID, chunkCount))
+		chunkCount++
+	}
+
+	for _, seg := range segments {
+		segTokens := estimateTokens(seg.text)
+
+		if len(current) > 0 && currentTokens+segTokens > tokenBudget {
+			flush()
+			current = overlapTail(current, overlapTokens)
+			currentTokens = 0
+			for _, s := range current {
+				currentTokens += estimateTokens(s.text)
+			}
+		}
+
+		current = append(current, seg)
+		currentTokens += segTokens
+	}
+	flush()
+
+	return chunks
+}
+
+// overlapTail returns the trailing segments of a chunk whose combined token
+// count is closest to (without much exceeding) overlapTokens, to seed the
+// next chunk with.
+func overlapTail(segments []segment, overlapTokens int) []segment {
+	if overlapTokens <= 0 {
+		return nil
+	}
+
+	tokens := 0
+	start := len(segments)
+	for start > 0 {
+		tokens += estimateTokens(segments[start-1].text)
+		start--
+		if tokens >= overlapTokens {
+			break
+		}
+	}
+	return append([]segment{}, segments[start:]...)
+}
+
+func buildChunk(segments []segment, docID string, index int) Chunk {
+	texts := make([]string, len(segments))
+	tokens := 0
+	for i, s := range segments {
+		texts[i] = s.text
+		tokens += estimateTokens(s.text)
+	}
+
+	return Chunk{
+		ID:          fmt.Sprintf("%s_chunk_%d", docID, index),
+		Content:     strings.Join(texts, "\n\n"),
+		DocID:       docID,
+		StartOffset: segments[0].start,
+		EndOffset:   segments[len(segments)-1].end,
+		TokenCount:  tokens,
+	}
+}
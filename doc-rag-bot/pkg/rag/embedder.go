@@ -0,0 +1,43 @@
+package rag
+
+import "fmt"
+
+// Embedder generates a vector embedding for a piece of text, abstracting the
+// indexer and retriever away from the concrete embedding backend.
+type Embedder interface {
+	GetEmbedding(text string) ([]float32, error)
+}
+
+// EmbedderAdapter identifies a supported embedding backend.
+type EmbedderAdapter string
+
+const (
+	EmbedderOllama EmbedderAdapter = "ollama"
+	EmbedderOpenAI EmbedderAdapter = "openai"
+)
+
+// EmbedderConfig selects and configures an Embedder.
+type EmbedderConfig struct {
+	Adapter EmbedderAdapter
+	BaseURL string
+	Model   string
+	APIKey  string
+}
+
+// NewEmbedder builds the Embedder selected by cfg.Adapter.
+func NewEmbedder(cfg EmbedderConfig) (Embedder, error) {
+	switch cfg.Adapter {
+	case "", EmbedderOllama:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("ollama embedder requires BaseURL")
+		}
+		return NewEmbeddingAPI(cfg.BaseURL, cfg.Model), nil
+	case EmbedderOpenAI:
+		if cfg.BaseURL == "" || cfg.APIKey == "" {
+			return nil, fmt.Errorf("openai embedder requires BaseURL and APIKey")
+		}
+		return newOpenAIEmbedder(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown embedder adapter %q", cfg.Adapter)
+	}
+}
@@ -1,57 +1,146 @@
 package rag
 
 import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
 // Document represents a document with its content and metadata
 type Document struct {
-	ID       string
-	Content  string
-	Filename string
-	Chunks   []Chunk
+	ID          string
+	Content     string
+	Filename    string
+	Chunks      []Chunk
+	ContentHash string // SHA-256 of Content, so IndexDirectory can skip re-embedding unchanged files
+	// Metadata is copied onto every Chunk produced from this Document (see
+	// indexDocument), so Filter can scope retrieval by arbitrary key/value
+	// pairs beyond DocID -- e.g. a reconcile trace's reconcileId/controller/
+	// namespace/name/status (see IngestReconcileTrace). Plain files indexed
+	// via IndexFile leave this nil.
+	Metadata map[string]string
 }
 
-// Chunk represents a chunk of text from a document
+// Chunk represents a chunk of text from a document. StartOffset/EndOffset
+// are byte offsets into the source document's Content, and TokenCount is
+// the estimated token count of Content, so downstream retrieval can
+// highlight source spans and re-rank on token-normalized similarity.
 type Chunk struct {
-	ID        string
-	Content   string
-	DocID     string
-	Embedding []float32
+	ID          string
+	Content     string
+	DocID       string
+	Embedding   []float32
+	StartOffset int
+	EndOffset   int
+	TokenCount  int
+	// Metadata is the owning Document's Metadata, copied at index time; see
+	// Document.Metadata.
+	Metadata map[string]string
 }
 
 // Indexer handles document indexing and chunking
 type Indexer struct {
-	Documents    map[string]*Document
-	Chunks       map[string]*Chunk
-	embeddingAPI *EmbeddingAPI
-	chunkSize    int
-	chunkOverlap int
+	Documents     map[string]*Document
+	Chunks        map[string]*Chunk
+	embedder      Embedder
+	tokenBudget   int
+	overlapTokens int
+
+	// semanticChunking and semanticSimilarityThreshold configure
+	// chunkDocumentSemantic; see NewIndexerWithSemanticChunking.
+	semanticChunking            bool
+	semanticSimilarityThreshold float64
+
+	// bm25 caches the corpus-aware BM25 index (term document frequencies,
+	// average document length) so Retriever doesn't rescan every chunk on
+	// every query. registerDocument/removeDocumentByID update it in place.
+	// bm25Dirty is set only by Load for a snapshot without persisted BM25
+	// stats; BM25() then rebuilds it from every chunk on next access.
+	bm25      *BM25Index
+	bm25Dirty bool
+
+	// store is the dense-retrieval backend Retriever queries instead of
+	// walking i.Chunks directly. Defaults to NewMemoryVectorStore; callers
+	// with a large corpus can swap in NewIVFFlatVectorStore via
+	// SetVectorStore for sub-linear queries.
+	store VectorStore
+
+	// journal and journalEnc are the open WAL file AddDocument/
+	// UpdateDocument/RemoveDocument append to (see appendJournal), and its
+	// gob encoder reused across calls so only one stream header is ever
+	// written. Both are nil until Load opens (or creates) one; an Indexer
+	// built directly with NewIndexer and never Load-ed has no journal, and
+	// mutations simply aren't durable until the next Save.
+	journal    *os.File
+	journalEnc *gob.Encoder
 }
 
-// NewIndexer creates a new document indexer
+// defaultSemanticSimilarityThreshold is the cosine similarity below which
+// adjacent paragraphs are split into separate chunks during semantic
+// chunking.
+const defaultSemanticSimilarityThreshold = 0.75
+
+// NewIndexer creates a new document indexer backed by Ollama embeddings.
 func NewIndexer(ollamaURL, embeddingModel string) *Indexer {
 	return &Indexer{
-		Documents:    make(map[string]*Document),
-		Chunks:       make(map[string]*Chunk),
-		embeddingAPI: NewEmbeddingAPI(ollamaURL, embeddingModel),
-		chunkSize:    500, // Smaller chunk size (was 1000)
-		chunkOverlap: 100, // Smaller overlap (was 200)
+		Documents:     make(map[string]*Document),
+		Chunks:        make(map[string]*Chunk),
+		embedder:      NewEmbeddingAPI(ollamaURL, embeddingModel),
+		tokenBudget:   256,
+		overlapTokens: 32,
+		store:         NewMemoryVectorStore(),
+	}
+}
+
+// NewIndexerWithConfig creates a new document indexer using the embedding
+// backend selected by cfg, so callers can swap EmbedderOllama for
+// EmbedderOpenAI with only config changes.
+func NewIndexerWithConfig(cfg EmbedderConfig) (*Indexer, error) {
+	embedder, err := NewEmbedder(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedder: %w", err)
+	}
+
+	return &Indexer{
+		Documents:     make(map[string]*Document),
+		Chunks:        make(map[string]*Chunk),
+		embedder:      embedder,
+		tokenBudget:   256,
+		overlapTokens: 32,
+		store:         NewMemoryVectorStore(),
+	}, nil
+}
+
+// NewIndexerWithSemanticChunking creates an indexer like NewIndexer, but
+// chunks documents by merging adjacent paragraphs while their embeddings
+// stay semantically similar (see chunkDocumentSemantic) instead of packing
+// purely by token budget. Pass 0 for similarityThreshold to use
+// defaultSemanticSimilarityThreshold.
+func NewIndexerWithSemanticChunking(ollamaURL, embeddingModel string, similarityThreshold float64) *Indexer {
+	if similarityThreshold <= 0 {
+		similarityThreshold = defaultSemanticSimilarityThreshold
 	}
+	idx := NewIndexer(ollamaURL, embeddingModel)
+	idx.semanticChunking = true
+	idx.semanticSimilarityThreshold = similarityThreshold
+	return idx
 }
 
-// IndexDirectory indexes all text files in a directory
+// IndexDirectory indexes all text files in a directory, skipping files whose
+// content hasn't changed since the last index (see IndexFile), and evicting
+// any previously indexed document whose file was deleted.
 func (i *Indexer) IndexDirectory(dirPath string) error {
 	files, err := ioutil.ReadDir(dirPath)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
+	seen := make(map[string]bool, len(files))
 	for _, file := range files {
 		if file.IsDir() {
 			continue
@@ -63,16 +152,57 @@ func (i *Indexer) IndexDirectory(dirPath string) error {
 		}
 
 		filePath := filepath.Join(dirPath, file.Name())
-		err := i.IndexFile(filePath)
-		if err != nil {
+		seen[filePath] = true
+		if err := i.IndexFile(filePath); err != nil {
 			return fmt.Errorf("failed to index file %s: %w", file.Name(), err)
 		}
 	}
 
+	i.evictMissing(dirPath, seen)
 	return nil
 }
 
-// IndexFile indexes a single text file
+// Reindex forces every file in dirPath to be re-embedded regardless of
+// ContentHash, for the REPL's "reindex" command (e.g. after switching
+// embedding models).
+func (i *Indexer) Reindex(dirPath string) error {
+	dirPath = filepath.Clean(dirPath)
+	for docID, doc := range i.Documents {
+		if filepath.Dir(doc.Filename) != dirPath {
+			continue
+		}
+		i.removeDocumentByID(docID)
+	}
+	return i.IndexDirectory(dirPath)
+}
+
+// evictMissing removes documents (and their chunks) previously indexed from
+// dirPath whose backing file is no longer present, so a persisted index
+// doesn't keep accumulating entries for deleted docs.
+func (i *Indexer) evictMissing(dirPath string, seen map[string]bool) {
+	dirPath = filepath.Clean(dirPath)
+	for docID, doc := range i.Documents {
+		if filepath.Dir(doc.Filename) != dirPath || seen[doc.Filename] {
+			continue
+		}
+		i.removeDocumentByID(docID)
+		logger.Info("Evicted deleted document", Fields{"name": docID})
+	}
+}
+
+// BM25 returns the indexer's cached BM25Index, rebuilding it first if
+// chunks have changed since the last build. Retriever uses this instead of
+// rescanning every chunk on every query.
+func (i *Indexer) BM25() *BM25Index {
+	if i.bm25 == nil || i.bm25Dirty {
+		i.bm25 = NewBM25Index(i.GetAllChunks())
+		i.bm25Dirty = false
+	}
+	return i.bm25
+}
+
+// IndexFile indexes a single text file, skipping re-embedding if its content
+// hash matches the previously indexed version.
 func (i *Indexer) IndexFile(filePath string) error {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -80,134 +210,169 @@ func (i *Indexer) IndexFile(filePath string) error {
 	}
 
 	docID := filepath.Base(filePath)
+	hash := contentHash(content)
+
+	if existing, ok := i.Documents[docID]; ok && existing.ContentHash == hash {
+		logger.Info("Skipping unchanged document", Fields{"name": docID})
+		return nil
+	}
+
 	doc := &Document{
-		ID:       docID,
-		Content:  string(content),
-		Filename: filePath,
-		Chunks:   []Chunk{},
+		ID:          docID,
+		Content:     string(content),
+		Filename:    filePath,
+		Chunks:      []Chunk{},
+		ContentHash: hash,
 	}
 
-	// Chunk the document
-	chunks := i.chunkDocument(doc)
+	n, err := i.indexDocument(doc)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Indexed document", Fields{"name": docID, "chunks": n})
+	return nil
+}
+
+// indexDocument chunks doc, embeds each chunk, evicts any previous version
+// of doc.ID already indexed, and registers the result -- in i.Chunks,
+// i.store, and the BM25 index, all kept current rather than marked stale.
+// It's the shared tail of IndexFile, IngestReconcileTrace, and
+// AddDocument/UpdateDocument -- anything that's already built a Document
+// just needs this done to it.
+func (i *Indexer) indexDocument(doc *Document) (int, error) {
+	chunks, err := i.chunkDocument(doc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to chunk document: %w", err)
+	}
 
-	// Generate embeddings for each chunk
 	for idx := range chunks {
-		embedding, err := i.embeddingAPI.GetEmbedding(chunks[idx].Content)
+		embedding, err := timeEmbedding(func() ([]float32, error) {
+			return i.embedder.GetEmbedding(chunks[idx].Content)
+		})
 		if err != nil {
-			return fmt.Errorf("failed to generate embedding for chunk: %w", err)
+			return 0, fmt.Errorf("failed to generate embedding for chunk: %w", err)
 		}
 		chunks[idx].Embedding = embedding
-		i.Chunks[chunks[idx].ID] = &chunks[idx]
+		chunks[idx].Metadata = doc.Metadata
 	}
-
 	doc.Chunks = chunks
-	i.Documents[docID] = doc
 
-	fmt.Printf("Indexed document: %s with %d chunks\n", docID, len(chunks))
-	return nil
-}
-
-// chunkDocument splits a document into chunks
-func (i *Indexer) chunkDocument(doc *Document) []Chunk {
-	content := doc.Content
-	var chunks []Chunk
+	i.removeDocumentByID(doc.ID)
+	i.registerDocument(doc)
+	indexedChunksTotal.WithLabelValues(doc.ID).Add(float64(len(chunks)))
 
-	// Split by paragraphs first
-	paragraphs := strings.Split(content, "\n\n")
+	return len(chunks), nil
+}
 
-	var currentChunk strings.Builder
-	chunkCount := 0
+// registerDocument inserts doc -- whose Chunks must already carry computed
+// embeddings -- into i.Documents/i.Chunks/i.store and adds its chunks to the
+// BM25 index in place (see BM25Index.addChunk), without touching any
+// previous version of doc.ID; callers that might be replacing one call
+// removeDocumentByID first. It never calls the embedder, so replayJournal
+// can use it to restore an already-indexed Document without re-embedding.
+func (i *Indexer) registerDocument(doc *Document) {
+	bm25 := i.BM25()
+	for idx := range doc.Chunks {
+		i.Chunks[doc.Chunks[idx].ID] = &doc.Chunks[idx]
+		i.store.Upsert(&doc.Chunks[idx])
+		bm25.addChunk(&doc.Chunks[idx])
+	}
+	i.Documents[doc.ID] = doc
+}
 
-	for _, para := range paragraphs {
-		// Skip empty paragraphs
-		if strings.TrimSpace(para) == "" {
-			continue
-		}
+// removeDocumentByID evicts docID's chunks from i.Chunks, i.store, and the
+// BM25 index (all updated in place, not just marked stale) and removes the
+// document itself. It's a no-op if docID isn't indexed.
+func (i *Indexer) removeDocumentByID(docID string) {
+	doc, ok := i.Documents[docID]
+	if !ok {
+		return
+	}
+	bm25 := i.BM25()
+	for idx := range doc.Chunks {
+		delete(i.Chunks, doc.Chunks[idx].ID)
+		i.store.Delete(doc.Chunks[idx].ID)
+		bm25.removeChunk(doc.Chunks[idx].ID)
+	}
+	delete(i.Documents, docID)
+}
 
-		// If adding this paragraph would exceed the chunk size,
-		// save the current chunk and start a new one
-		if currentChunk.Len() > 0 && currentChunk.Len()+len(para) > i.chunkSize {
-			chunkID := fmt.Sprintf("%s_chunk_%d", doc.ID, chunkCount)
-			chunk := Chunk{
-				ID:      chunkID,
-				Content: currentChunk.String(),
-				DocID:   doc.ID,
-			}
-			chunks = append(chunks, chunk)
-			chunkCount++
-			currentChunk.Reset()
-		}
+// AddDocument indexes doc -- computing chunk embeddings, updating the BM25
+// corpus stats and VectorStore in place -- and durably journals the
+// mutation (see appendJournal), so new content (e.g. a reconcile-trace
+// document arriving from the parser; see IngestReconcileTrace) becomes
+// queryable within seconds without re-embedding the rest of the corpus, and
+// survives a crash before the next Save. If doc.ID is already indexed, its
+// previous version is evicted first, same as UpdateDocument.
+func (i *Indexer) AddDocument(doc Document) error {
+	if doc.ID == "" {
+		return fmt.Errorf("document is missing an ID")
+	}
+	if doc.ContentHash == "" {
+		doc.ContentHash = contentHash([]byte(doc.Content))
+	}
 
-		// Add paragraph to current chunk
-		if currentChunk.Len() > 0 {
-			currentChunk.WriteString("\n\n")
-		}
-		currentChunk.WriteString(para)
-
-		// If this paragraph alone is bigger than the chunk size,
-		// we need to split it further
-		if currentChunk.Len() > i.chunkSize {
-			// Split by sentences
-			sentences := splitIntoSentences(currentChunk.String())
-			currentChunk.Reset()
-
-			var sentenceChunk strings.Builder
-			for _, sentence := range sentences {
-				if sentenceChunk.Len()+len(sentence) > i.chunkSize {
-					if sentenceChunk.Len() > 0 {
-						chunkID := fmt.Sprintf("%s_chunk_%d", doc.ID, chunkCount)
-						chunk := Chunk{
-							ID:      chunkID,
-							Content: sentenceChunk.String(),
-							DocID:   doc.ID,
-						}
-						chunks = append(chunks, chunk)
-						chunkCount++
-						sentenceChunk.Reset()
-					}
-				}
-
-				if sentenceChunk.Len() > 0 {
-					sentenceChunk.WriteString(" ")
-				}
-				sentenceChunk.WriteString(sentence)
-			}
-
-			if sentenceChunk.Len() > 0 {
-				currentChunk.WriteString(sentenceChunk.String())
-			}
-		}
+	if _, err := i.indexDocument(&doc); err != nil {
+		return fmt.Errorf("failed to add document %s: %w", doc.ID, err)
 	}
 
-	// Don't forget the last chunk
-	if currentChunk.Len() > 0 {
-		chunkID := fmt.Sprintf("%s_chunk_%d", doc.ID, chunkCount)
-		chunk := Chunk{
-			ID:      chunkID,
-			Content: currentChunk.String(),
-			DocID:   doc.ID,
-		}
-		chunks = append(chunks, chunk)
+	if err := i.appendJournal(journalEntry{Op: journalUpsert, Doc: &doc}); err != nil {
+		return err
 	}
+	return nil
+}
 
-	return chunks
+// UpdateDocument re-indexes doc under its existing ID, exactly like
+// AddDocument. It's a distinct method (rather than callers just calling
+// AddDocument again) because "this ID should already exist" is useful
+// documentation at the call site even though the implementation is
+// identical -- indexDocument evicts any previous version either way.
+func (i *Indexer) UpdateDocument(doc Document) error {
+	return i.AddDocument(doc)
 }
 
-// Helper function to split text into sentences
-func splitIntoSentences(text string) []string {
-	// Simple sentence splitting by common sentence terminators
-	// This is a basic implementation - could be improved with NLP libraries
-	re := regexp.MustCompile(`[.!?]\s+`)
-	sentences := re.Split(text, -1)
+// RemoveDocument evicts docID's chunks from the index, BM25 stats, and
+// VectorStore, and durably journals the removal.
+func (i *Indexer) RemoveDocument(docID string) error {
+	i.removeDocumentByID(docID)
+	return i.appendJournal(journalEntry{Op: journalRemove, DocID: docID})
+}
 
-	var result []string
-	for _, s := range sentences {
-		s = strings.TrimSpace(s)
-		if s != "" {
-			result = append(result, s)
-		}
+// contentHash returns the hex-encoded SHA-256 of content, used as
+// Document.ContentHash to detect unchanged files across runs.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkDocument splits a document into chunks. By default it uses a
+// two-pass strategy: segment the document into sentences (keeping fenced
+// code blocks and YAML block scalars atomic), then greedily pack those
+// segments into chunks bounded by a token budget with a sliding token-based
+// overlap between chunks. If semanticChunking is enabled, it instead uses
+// chunkDocumentSemantic.
+func (i *Indexer) chunkDocument(doc *Document) ([]Chunk, error) {
+	if i.semanticChunking {
+		return i.chunkDocumentSemantic(doc)
+	}
+	segments := segmentDocument(doc.Content)
+	return packSegments(segments, doc.ID, i.tokenBudget, i.overlapTokens), nil
+}
+
+// Store returns the Indexer's VectorStore, for Retriever to query.
+func (i *Indexer) Store() VectorStore {
+	return i.store
+}
+
+// SetVectorStore swaps the Indexer's VectorStore, re-upserting every
+// already-indexed chunk into it so it starts populated. Call this right
+// after construction, before indexing, to avoid the re-upsert cost.
+func (i *Indexer) SetVectorStore(store VectorStore) {
+	i.store = store
+	for _, chunk := range i.Chunks {
+		i.store.Upsert(chunk)
 	}
-	return result
 }
 
 // GetAllChunks returns all indexed chunks
@@ -0,0 +1,266 @@
+package rag
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// defaultNProbe is how many of the nearest centroids IVFFlatVectorStore
+// scans per query. Higher values trade query speed for recall.
+const defaultNProbe = 4
+
+// defaultNList is the default number of inverted-file partitions, used when
+// NewIVFFlatVectorStore is given nlist <= 0.
+const defaultNList = 16
+
+// lloydIterations bounds how many k-means refinement passes rebuild runs.
+// A handful of iterations gets most of the clustering benefit without
+// rebuild becoming the new bottleneck on a large corpus.
+const lloydIterations = 4
+
+// ivfSnapshot is the on-disk gob encoding of an IVFFlatVectorStore.
+type ivfSnapshot struct {
+	Chunks     map[string]*Chunk
+	Centroids  [][]float32
+	Assignment map[string]int
+}
+
+// IVFFlatVectorStore is an approximate-nearest-neighbor VectorStore: chunks
+// are partitioned into NList clusters (inverted lists) by their embedding,
+// and a Query only scans the NProbe clusters whose centroid is closest to
+// the query embedding, instead of every chunk -- sub-linear in practice
+// once the corpus is large relative to NList. There's no go.mod in this
+// repo to pin a real HNSW/FAISS binding to, so this is a small pure-Go
+// IVF-flat: simpler than HNSW, and good enough to demonstrate the
+// sub-linear-query path this VectorStore interface exists for.
+type IVFFlatVectorStore struct {
+	mu     sync.RWMutex
+	chunks map[string]*Chunk
+
+	nlist  int
+	nprobe int
+
+	centroids  [][]float32
+	assignment map[string]int // chunk ID -> index into centroids
+
+	// dirty marks the partitioning stale after an Upsert; Query rebuilds
+	// lazily rather than on every mutation. Delete only drops the chunk from
+	// its cluster and leaves the centroids as they are.
+	dirty bool
+}
+
+// NewIVFFlatVectorStore creates an IVFFlatVectorStore with nlist inverted
+// lists (<= 0 uses defaultNList) and defaultNProbe lists scanned per query.
+func NewIVFFlatVectorStore(nlist int) *IVFFlatVectorStore {
+	if nlist <= 0 {
+		nlist = defaultNList
+	}
+	return &IVFFlatVectorStore{
+		chunks: make(map[string]*Chunk),
+		nlist:  nlist,
+		nprobe: defaultNProbe,
+	}
+}
+
+func (s *IVFFlatVectorStore) Upsert(chunk *Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks[chunk.ID] = chunk
+	s.dirty = true
+	return nil
+}
+
+func (s *IVFFlatVectorStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chunks, id)
+	delete(s.assignment, id)
+	return nil
+}
+
+func (s *IVFFlatVectorStore) Query(embedding []float32, topK int, filter Filter) ([]ChunkScore, error) {
+	s.mu.Lock()
+	if s.dirty || s.centroids == nil {
+		s.rebuild()
+	}
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	probeSet := s.nearestCentroids(embedding, s.nprobe)
+
+	scores := make([]ChunkScore, 0, len(s.chunks))
+	for id, cluster := range s.assignment {
+		if !probeSet[cluster] {
+			continue
+		}
+		chunk, ok := s.chunks[id]
+		if !ok || !filter.matches(chunk) {
+			continue
+		}
+		scores = append(scores, ChunkScore{Chunk: chunk, Score: cosineSimilarity(embedding, chunk.Embedding)})
+	}
+
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	if topK > 0 && len(scores) > topK {
+		scores = scores[:topK]
+	}
+	return scores, nil
+}
+
+// nearestCentroids returns the set of up to n centroid indices closest to
+// embedding, by cosine similarity.
+func (s *IVFFlatVectorStore) nearestCentroids(embedding []float32, n int) map[int]bool {
+	type centroidDist struct {
+		idx   int
+		score float32
+	}
+	dists := make([]centroidDist, len(s.centroids))
+	for i, c := range s.centroids {
+		dists[i] = centroidDist{idx: i, score: cosineSimilarity(embedding, c)}
+	}
+	sort.Slice(dists, func(i, j int) bool { return dists[i].score > dists[j].score })
+
+	if n > len(dists) {
+		n = len(dists)
+	}
+	probe := make(map[int]bool, n)
+	for i := 0; i < n; i++ {
+		probe[dists[i].idx] = true
+	}
+	return probe
+}
+
+// rebuild partitions every chunk into s.nlist clusters via a few rounds of
+// Lloyd's algorithm (k-means) over cosine similarity, seeded from evenly
+// spaced chunks (in chunk ID order) for a deterministic, cheap
+// initialization -- a reasonable stand-in for k-means++ here.
+func (s *IVFFlatVectorStore) rebuild() {
+	chunks := make([]*Chunk, 0, len(s.chunks))
+	for _, c := range s.chunks {
+		chunks = append(chunks, c)
+	}
+	sort.Slice(chunks, func(a, b int) bool { return chunks[a].ID < chunks[b].ID })
+
+	nlist := s.nlist
+	if nlist > len(chunks) {
+		nlist = len(chunks)
+	}
+	if nlist == 0 {
+		s.centroids = [][]float32{}
+		s.assignment = make(map[string]int)
+		s.dirty = false
+		return
+	}
+
+	centroids := make([][]float32, nlist)
+	step := len(chunks) / nlist
+	if step == 0 {
+		step = 1
+	}
+	for i := 0; i < nlist; i++ {
+		centroids[i] = append([]float32(nil), chunks[(i*step)%len(chunks)].Embedding...)
+	}
+
+	assignment := make(map[string]int, len(chunks))
+	for iter := 0; iter < lloydIterations; iter++ {
+		for _, c := range chunks {
+			best, bestScore := 0, float32(-2)
+			for ci, centroid := range centroids {
+				if score := cosineSimilarity(c.Embedding, centroid); score > bestScore {
+					best, bestScore = ci, score
+				}
+			}
+			assignment[c.ID] = best
+		}
+
+		sums := make([][]float64, nlist)
+		counts := make([]int, nlist)
+		for _, c := range chunks {
+			cluster := assignment[c.ID]
+			if sums[cluster] == nil {
+				sums[cluster] = make([]float64, len(c.Embedding))
+			}
+			for i, v := range c.Embedding {
+				sums[cluster][i] += float64(v)
+			}
+			counts[cluster]++
+		}
+		for ci := range centroids {
+			if counts[ci] == 0 {
+				continue // empty cluster this round; keep its previous centroid
+			}
+			newCentroid := make([]float32, len(sums[ci]))
+			for i, sum := range sums[ci] {
+				newCentroid[i] = float32(sum / float64(counts[ci]))
+			}
+			centroids[ci] = newCentroid
+		}
+	}
+
+	s.centroids = centroids
+	s.assignment = assignment
+	s.dirty = false
+}
+
+// Persist writes the store's chunks, centroids, and cluster assignment to
+// path, so Load can skip re-clustering the corpus on the next run.
+func (s *IVFFlatVectorStore) Persist(path string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".rag-ivf-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for vector store: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	snapshot := ivfSnapshot{Chunks: s.chunks, Centroids: s.centroids, Assignment: s.assignment}
+	if err := gob.NewEncoder(tmp).Encode(snapshot); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode vector store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp vector store file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save vector store to %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load replaces the store's contents with what's persisted at path. It is
+// not an error for path not to exist yet.
+func (s *IVFFlatVectorStore) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open vector store file: %w", err)
+	}
+	defer f.Close()
+
+	var snapshot ivfSnapshot
+	if err := gob.NewDecoder(f).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode vector store file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks = snapshot.Chunks
+	if s.chunks == nil {
+		s.chunks = make(map[string]*Chunk)
+	}
+	s.centroids = snapshot.Centroids
+	s.assignment = snapshot.Assignment
+	s.dirty = len(s.centroids) == 0
+	return nil
+}
@@ -0,0 +1,125 @@
+package rag
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+)
+
+// journalOp identifies the kind of mutation a journalEntry records.
+type journalOp string
+
+const (
+	journalUpsert journalOp = "upsert"
+	journalRemove journalOp = "remove"
+)
+
+// journalEntry is one durable record of an AddDocument/UpdateDocument/
+// RemoveDocument call, gob-encoded and fsynced to the journal file before
+// the call returns (see appendJournal). Doc is only set for journalUpsert,
+// and is recorded after indexing -- its Chunks already carry computed
+// embeddings -- so replaying it never re-calls the embedder.
+type journalEntry struct {
+	Op    journalOp
+	Doc   *Document
+	DocID string
+}
+
+// journalPath derives a WAL journal's path from the snapshot path Save/Load
+// are called with, so the two files always travel together.
+func journalPath(snapshotPath string) string {
+	return snapshotPath + ".wal"
+}
+
+// openJournal opens (creating if needed) path for appending subsequent
+// mutations. Load calls this after replaying whatever the journal already
+// held, so the same file keeps accumulating new AddDocument/UpdateDocument/
+// RemoveDocument calls until the next Save truncates it.
+func (i *Indexer) openJournal(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open index journal %s: %w", path, err)
+	}
+	i.journal = f
+	i.journalEnc = gob.NewEncoder(f)
+	return nil
+}
+
+// appendJournal durably records entry -- gob-encode, then an explicit fsync
+// -- so a crash immediately after AddDocument/UpdateDocument/RemoveDocument
+// return still has the mutation recoverable by the next Load. It's a no-op
+// if no journal is open, i.e. this Indexer has never been Load-ed: without
+// a snapshot path there's nowhere the journal would be replayed from
+// anyway.
+func (i *Indexer) appendJournal(entry journalEntry) error {
+	if i.journalEnc == nil {
+		return nil
+	}
+	if err := i.journalEnc.Encode(entry); err != nil {
+		return fmt.Errorf("failed to append index journal entry: %w", err)
+	}
+	if err := i.journal.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync index journal: %w", err)
+	}
+	return nil
+}
+
+// truncateJournal empties the open journal in place once Save has written a
+// fresh snapshot covering everything in it -- otherwise Load would replay
+// the same mutations on top of a snapshot that already includes them. It
+// also replaces journalEnc with a fresh gob.Encoder: a gob.Encoder only ever
+// transmits a given type's wire descriptor once per encoder instance, so
+// reusing the encoder created in openJournal against a now-empty file would
+// write entries with no type descriptor at all, and no decoder (not even
+// the one about to replay the same file) can decode them.
+func (i *Indexer) truncateJournal() error {
+	if i.journal == nil {
+		return nil
+	}
+	if err := i.journal.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate index journal: %w", err)
+	}
+	if _, err := i.journal.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind index journal: %w", err)
+	}
+	i.journalEnc = gob.NewEncoder(i.journal)
+	return nil
+}
+
+// replayJournal reads every entry from path in order and re-applies it
+// directly against i -- not through AddDocument/RemoveDocument, which would
+// re-journal what's already journaled -- so Load can catch up on mutations
+// since the last Save snapshot. It's not an error for path not to exist.
+func (i *Indexer) replayJournal(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open index journal %s: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	replayed := 0
+	for {
+		var entry journalEntry
+		if err := dec.Decode(&entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return replayed, fmt.Errorf("failed to decode index journal entry: %w", err)
+		}
+
+		switch entry.Op {
+		case journalUpsert:
+			i.removeDocumentByID(entry.Doc.ID)
+			i.registerDocument(entry.Doc)
+		case journalRemove:
+			i.removeDocumentByID(entry.DocID)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
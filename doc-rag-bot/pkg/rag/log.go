@@ -0,0 +1,29 @@
+package rag
+
+import "doc-rag-bot/pkg/logging"
+
+// Fields is a convenience alias so callers elsewhere in this package don't
+// need to import pkg/logging directly.
+type Fields = logging.Fields
+
+// logger is shared by the indexer and retriever so their trace lands under
+// one "rag" component. SetLokiSink lets cmd/main.go opt into shipping it to
+// Loki alongside the rest of its reconcile trace.
+var logger = logging.NewLogger("rag", nil)
+
+// SetLokiSink mirrors all subsequent rag package log lines to a Loki push
+// API at url, under the given stream labels.
+func SetLokiSink(url string, labels map[string]string) {
+	logger = logging.NewLogger("rag", logging.NewLokiSink(url, labels))
+}
+
+// verboseLogging gates log lines that are only useful when debugging
+// retrieval itself (e.g. a query expander's original vs. expanded text),
+// too noisy to leave on by default once there's more than one expander in
+// play. Off by default; SetVerbose(true) turns it on.
+var verboseLogging = false
+
+// SetVerbose toggles verboseLogging.
+func SetVerbose(v bool) {
+	verboseLogging = v
+}
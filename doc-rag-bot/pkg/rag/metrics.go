@@ -0,0 +1,36 @@
+package rag
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// indexedChunksTotal counts chunks produced by the indexer, by document ID.
+	indexedChunksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "doc_rag_bot_indexed_chunks_total",
+		Help: "Number of chunks produced by the indexer, by document.",
+	}, []string{"doc_id"})
+
+	// embeddingDuration tracks how long a single GetEmbedding call takes.
+	embeddingDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "doc_rag_bot_embedding_duration_seconds",
+		Help:    "Latency of a single embedding call.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	prometheus.MustRegister(indexedChunksTotal, embeddingDuration)
+}
+
+// timeEmbedding records how long fn takes in embeddingDuration and returns
+// fn's result unchanged.
+func timeEmbedding(fn func() ([]float32, error)) ([]float32, error) {
+	start := time.Now()
+	defer func() {
+		embeddingDuration.Observe(time.Since(start).Seconds())
+	}()
+	return fn()
+}
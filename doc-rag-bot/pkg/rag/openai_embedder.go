@@ -0,0 +1,76 @@
+package rag
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// openAIEmbedder talks to the OpenAI-compatible /v1/embeddings endpoint.
+type openAIEmbedder struct {
+	baseURL string
+	model   string
+	apiKey  string
+	client  *http.Client
+}
+
+func newOpenAIEmbedder(cfg EmbedderConfig) *openAIEmbedder {
+	return &openAIEmbedder{
+		baseURL: cfg.BaseURL,
+		model:   cfg.Model,
+		apiKey:  cfg.APIKey,
+		client:  &http.Client{},
+	}
+}
+
+type openAIEmbeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// GetEmbedding implements Embedder.
+func (e *openAIEmbedder) GetEmbedding(text string) ([]float32, error) {
+	reqBody, err := json.Marshal(openAIEmbeddingRequest{
+		Model: e.model,
+		Input: preprocessText(text),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.baseURL+"/v1/embeddings", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to embedding API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embedding API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var parsed openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Data) == 0 {
+		return nil, fmt.Errorf("openai embedding response had no data")
+	}
+
+	return parsed.Data[0].Embedding, nil
+}
@@ -0,0 +1,178 @@
+package rag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReconcileStepTrace mirrors the fields of kube-controller-viz's
+// parser.ReconcileStep that matter for RAG ingestion -- the same
+// field-schema-mirroring approach pkg/logging already uses for
+// parser.LogEntry -- rather than importing that package directly, since
+// this repo keeps doc-rag-bot and kube-controller-viz as independent
+// sub-projects. A caller holding parser.ReconcileStep values maps them
+// across field-by-field.
+//
+// ReconcileID/Controller/ControllerKind/Namespace/Name are only needed to
+// group a flat slice of steps spanning many reconciles (see
+// GroupReconcileSteps) back into one trace per ReconcileID; a caller that
+// already has a single reconcile's steps grouped can leave them zero and
+// populate ReconcileTrace's corresponding fields directly instead.
+type ReconcileStepTrace struct {
+	ReconcileID    string
+	Controller     string
+	ControllerKind string
+	Namespace      string
+	Name           string
+	StepType       string
+	Description    string
+	Timestamp      int64  // unix millis
+	Duration       int64  // milliseconds
+	Status         string // started, completed, failed
+}
+
+// ReconcileTrace is one reconcile's worth of steps (a parser.ControllerState
+// filtered down to a single ReconcileID) plus the controller identity those
+// steps share, ready for IngestReconcileTrace to render and index.
+type ReconcileTrace struct {
+	ReconcileID    string
+	Controller     string
+	ControllerKind string
+	Namespace      string
+	Name           string
+	Steps          []ReconcileStepTrace
+}
+
+// IngestReconcileTrace renders trace into a synthetic Document -- a
+// timestamp-ordered, human-readable account of one reconcile's steps, with
+// failures called out -- and indexes it exactly like a file from
+// IndexDirectory would be. The document's chunks carry reconcileId/
+// controller/controllerKind/namespace/name/status metadata, so
+// RetrieveRelevantChunksWithFilter(query, topK, Filter{Metadata:
+// map[string]string{"name": "foo"}}) can scope a query to just that
+// resource's reconciles, e.g. answering "why did the last reconcile for
+// MachineSet foo fail?" with the exact step trace instead of raw log lines.
+func (i *Indexer) IngestReconcileTrace(trace ReconcileTrace) error {
+	if trace.ReconcileID == "" {
+		return fmt.Errorf("reconcile trace is missing a ReconcileID")
+	}
+
+	docID := "reconcile-" + trace.ReconcileID
+	content, status, durationTotal := renderReconcileTrace(trace)
+
+	doc := Document{
+		ID:          docID,
+		Content:     content,
+		Filename:    docID,
+		ContentHash: contentHash([]byte(content)),
+		Metadata: map[string]string{
+			"reconcileId":    trace.ReconcileID,
+			"controller":     trace.Controller,
+			"controllerKind": trace.ControllerKind,
+			"namespace":      trace.Namespace,
+			"name":           trace.Name,
+			"status":         status,
+		},
+	}
+
+	// AddDocument (rather than indexDocument directly) journals the
+	// document too, so a crash between this ingest and the next Save()
+	// doesn't silently lose it -- the exact use case chunk4-6's WAL
+	// journal was added for.
+	if err := i.AddDocument(doc); err != nil {
+		return fmt.Errorf("failed to index reconcile trace %s: %w", trace.ReconcileID, err)
+	}
+	n := len(i.Documents[docID].Chunks)
+
+	logger.Info("Indexed reconcile trace", Fields{
+		"reconcileID":   trace.ReconcileID,
+		"controller":    trace.Controller,
+		"namespace":     trace.Namespace,
+		"name":          trace.Name,
+		"status":        status,
+		"durationTotal": durationTotal,
+		"chunks":        n,
+	})
+	return nil
+}
+
+// GroupReconcileSteps groups a flat slice of steps spanning many reconciles
+// -- e.g. a mirrored copy of kube-controller-viz's
+// parser.ControllerState.RecentSteps -- into one ReconcileTrace per
+// ReconcileID, preserving the order each ReconcileID first appears in steps
+// so the result is deterministic regardless of any map iteration upstream.
+func GroupReconcileSteps(steps []ReconcileStepTrace) []ReconcileTrace {
+	order := make([]string, 0, len(steps))
+	byID := make(map[string]*ReconcileTrace, len(steps))
+	for _, step := range steps {
+		trace, ok := byID[step.ReconcileID]
+		if !ok {
+			trace = &ReconcileTrace{
+				ReconcileID:    step.ReconcileID,
+				Controller:     step.Controller,
+				ControllerKind: step.ControllerKind,
+				Namespace:      step.Namespace,
+				Name:           step.Name,
+			}
+			byID[step.ReconcileID] = trace
+			order = append(order, step.ReconcileID)
+		}
+		trace.Steps = append(trace.Steps, step)
+	}
+
+	traces := make([]ReconcileTrace, len(order))
+	for i, id := range order {
+		traces[i] = *byID[id]
+	}
+	return traces
+}
+
+// IngestReconcileSteps groups steps via GroupReconcileSteps and indexes each
+// resulting ReconcileTrace via IngestReconcileTrace -- the entry point for a
+// caller holding one ControllerState poll's worth of flat, ungrouped steps
+// rather than traces already split by reconcile.
+func (i *Indexer) IngestReconcileSteps(steps []ReconcileStepTrace) error {
+	for _, trace := range GroupReconcileSteps(steps) {
+		if err := i.IngestReconcileTrace(trace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderReconcileTrace builds the deterministic text form of trace (steps
+// ordered by timestamp, failures highlighted) along with the overall
+// status ("failed" if any step failed, else the last step's status) and
+// total duration across all steps, in milliseconds.
+func renderReconcileTrace(trace ReconcileTrace) (content string, status string, durationTotal int64) {
+	steps := append([]ReconcileStepTrace(nil), trace.Steps...)
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Timestamp < steps[j].Timestamp })
+
+	status = "completed"
+	for _, step := range steps {
+		durationTotal += step.Duration
+		if step.Status == "failed" {
+			status = "failed"
+		} else if status != "failed" {
+			status = step.Status
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reconcile %s for %s %s/%s (controller: %s)\n", trace.ReconcileID, trace.ControllerKind, trace.Namespace, trace.Name, trace.Controller)
+	fmt.Fprintf(&b, "Status: %s, total duration: %dms, steps: %d\n\n", status, durationTotal, len(steps))
+
+	for _, step := range steps {
+		ts := time.UnixMilli(step.Timestamp).UTC().Format(time.RFC3339)
+		line := fmt.Sprintf("[%s] %s: %s (%dms) -- %s", ts, step.StepType, step.Description, step.Duration, step.Status)
+		if step.Status == "failed" {
+			line = "FAILED: " + line
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	return b.String(), status, durationTotal
+}
@@ -0,0 +1,98 @@
+package rag
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"doc-rag-bot/pkg/llm"
+)
+
+// Reranker asks an LLM to score each retrieved chunk's relevance to the
+// query on a 0-10 scale, then keeps only the top-N by score. This trades
+// extra LLM calls for higher precision than embedding+BM25 fusion alone can
+// offer, at the cost of one generation call per candidate chunk.
+type Reranker struct {
+	client *llm.OllamaClient
+	topN   int
+}
+
+// NewReranker creates a Reranker that scores candidates with model via
+// ollamaURL, keeping the top topN.
+func NewReranker(ollamaURL, model string, topN int) *Reranker {
+	return &Reranker{
+		client: llm.NewOllamaClient(ollamaURL, model),
+		topN:   topN,
+	}
+}
+
+// Rerank scores each candidate chunk's relevance to query and returns the
+// top r.topN, ordered by score descending. If there are already no more
+// candidates than topN, it returns them unchanged, without scoring them.
+func (r *Reranker) Rerank(query string, candidates []string) ([]string, error) {
+	if len(candidates) <= r.topN {
+		return candidates, nil
+	}
+
+	type scoredChunk struct {
+		text  string
+		score int
+	}
+
+	scored := make([]scoredChunk, len(candidates))
+	for i, candidate := range candidates {
+		score, err := r.scoreRelevance(query, candidate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to score candidate %d: %w", i, err)
+		}
+		scored[i] = scoredChunk{text: candidate, score: score}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+
+	result := make([]string, r.topN)
+	for i := 0; i < r.topN; i++ {
+		result[i] = scored[i].text
+	}
+	return result, nil
+}
+
+// scoreRelevance asks the LLM to rate how relevant chunk is to query on a
+// 0-10 scale, parsing the first integer in its reply.
+func (r *Reranker) scoreRelevance(query, chunk string) (int, error) {
+	prompt := fmt.Sprintf(`Rate how relevant the following passage is to answering the question, on a scale from 0 (irrelevant) to 10 (directly answers it). Respond with ONLY the integer score, nothing else.
+
+QUESTION: %s
+
+PASSAGE:
+%s`, query, chunk)
+
+	response, err := r.client.Generate(prompt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get relevance score: %w", err)
+	}
+
+	return parseScore(response)
+}
+
+// parseScore extracts the first integer 0-10 found in the model's reply,
+// since models occasionally wrap the number in a sentence despite being
+// asked for a bare integer, and clamps it to the expected range.
+func parseScore(response string) (int, error) {
+	for _, field := range strings.Fields(response) {
+		field = strings.Trim(field, ".,!*")
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n > 10 {
+			n = 10
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("no numeric score found in response: %q", response)
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"math"
 	"sort"
-	"strings"
 )
 
-// Retriever handles retrieving relevant document chunks
+// Retriever handles retrieving relevant document chunks using a hybrid of
+// dense (embedding cosine similarity) and sparse (BM25) search, fused with
+// reciprocal rank fusion. Both rankings score the query as rewritten by
+// expander (see QueryExpander), not necessarily the user's literal text.
 type Retriever struct {
-	indexer *Indexer
+	indexer  *Indexer
+	expander QueryExpander
 }
 
 // ChunkScore represents a chunk with its relevance score
@@ -18,146 +21,259 @@ type ChunkScore struct {
 	Score float32
 }
 
-// NewRetriever creates a new retriever
+// rrfK is the reciprocal-rank-fusion smoothing constant: a higher k flattens
+// the contribution of top-ranked results relative to lower-ranked ones.
+const rrfK = 60
+
+// NewRetriever creates a new retriever with no query expansion (the query
+// is embedded/scored as-is).
 func NewRetriever(indexer *Indexer) *Retriever {
 	return &Retriever{
-		indexer: indexer,
+		indexer:  indexer,
+		expander: noopExpander{},
 	}
 }
 
-// Add BM25 scoring for keyword matching
-func calculateBM25Score(query string, chunk *Chunk) float32 {
-	// Constants for BM25
-	const k1 = 1.2
-	const b = 0.75
-	const avgDocLength = 500.0 // This should ideally be calculated from your corpus
-
-	// Tokenize query and document
-	queryTerms := strings.Fields(strings.ToLower(query))
-	docTerms := strings.Fields(strings.ToLower(chunk.Content))
-
-	docLength := float32(len(docTerms))
-
-	// Count term frequencies
-	termFreq := make(map[string]int)
-	for _, term := range docTerms {
-		termFreq[term]++
-	}
-
-	// Calculate BM25 score
-	var score float32
-	for _, term := range queryTerms {
-		if freq, exists := termFreq[term]; exists {
-			// Calculate IDF - in a real implementation, this would use corpus statistics
-			// Here we use a simplified approach
-			idf := float32(1.0) // Simplified IDF
-
-			// BM25 term scoring formula
-			numerator := float32(freq) * (k1 + 1)
-			denominator := float32(freq) + k1*(1-b+b*(docLength/avgDocLength))
-			score += idf * (numerator / denominator)
-		}
-	}
-
-	return score
+// NewRetrieverWithExpander creates a Retriever that rewrites every query
+// through expander before embedding/BM25 scoring -- e.g. a SynonymExpander
+// or HyDEExpander. Injecting it at construction (rather than a package-level
+// default) lets tests stub QueryExpander.
+func NewRetrieverWithExpander(indexer *Indexer, expander QueryExpander) *Retriever {
+	return &Retriever{indexer: indexer, expander: expander}
 }
 
-// Update the query expansion function to be more generic
-func expandQuery(query string) string {
-	// Split the query into terms
-	// terms := strings.Fields(strings.ToLower(query))
-
-	// // Common technical troubleshooting terms
-	// troubleshootingTerms := map[string]bool{
-	// 	"troubleshoot": true,
-	// 	"debug":        true,
-	// 	"fix":          true,
-	// 	"issue":        true,
-	// 	"problem":      true,
-	// 	"error":        true,
-	// 	"fail":         true,
-	// 	"failure":      true,
-	// }
-
-	// // Check if this is a troubleshooting query
-	// isTroubleshooting := false
-	// for _, term := range terms {
-	// 	if troubleshootingTerms[term] {
-	// 		isTroubleshooting = true
-	// 		break
-	// 	}
-	// }
-
-	// // Expand with generic terms based on query type
-	// expanded := query
-	// if isTroubleshooting {
-	// 	expanded += " resolve solution steps guide how-to fix repair"
-	// }
-
-	return query
-}
+// defaultMMRLambda balances relevance against diversity in
+// RetrieveRelevantChunksMMR when the caller doesn't have an opinion: equal
+// weight on relevance and diversity, enough to stop near-duplicate chunks
+// from the same document crowding out everything else.
+const defaultMMRLambda = 0.5
 
-// Update RetrieveRelevantChunks to use the generic query expansion
-func (r *Retriever) RetrieveRelevantChunks(query string, topK int) ([]string, error) {
+// rankedCandidates runs the shared dense+BM25 hybrid ranking (query
+// expansion, embedding, reciprocal rank fusion) used by RetrieveRelevantChunks,
+// RetrieveRelevantChunksMMR, and RetrieveRelevantChunksWithFilter, returning
+// the full fused ranking plus the query embedding so MMR can measure
+// relevance without re-embedding. filter scopes both rankings to a subset
+// of the corpus (e.g. a single source document); the zero-value Filter
+// matches everything.
+func (r *Retriever) rankedCandidates(query string, filter Filter) ([]ChunkScore, []float32, error) {
 	// Expand the query with related terms
-	expandedQuery := expandQuery(query)
+	expandedQuery, err := r.expander.Expand(query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to expand query: %w", err)
+	}
 
-	if expandedQuery != query {
-		fmt.Printf("Original query: %s\nExpanded query: %s\n", query, expandedQuery)
+	if verboseLogging && expandedQuery != query {
+		logger.Info("Expanded query", Fields{"query": query, "expandedQuery": expandedQuery})
 	}
 
-	// Generate embedding for the query
-	queryEmbedding, err := r.indexer.embeddingAPI.GetEmbedding(expandedQuery)
+	// Generate embedding for the (possibly expanded) query
+	queryEmbedding, err := r.indexer.embedder.GetEmbedding(expandedQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding for query: %w", err)
+		return nil, nil, fmt.Errorf("failed to generate embedding for query: %w", err)
 	}
 
-	// Get all chunks
-	allChunks := r.indexer.GetAllChunks()
-	fmt.Printf("Total chunks available: %d\n", len(allChunks))
+	// Dense ranking: the indexer's VectorStore handles scoring (and, for an
+	// ANN-backed store, only scans a subset of the corpus) instead of this
+	// method walking every chunk itself. topK=0 means "no limit" -- the
+	// full ranking is needed for reciprocal rank fusion below.
+	denseScores, err := r.indexer.Store().Query(queryEmbedding, 0, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query vector store: %w", err)
+	}
+	logger.Info("Retrieving relevant chunks", Fields{"candidates": len(denseScores)})
+
+	// Sparse ranking: BM25 over the same chunks and the same expanded query
+	// text used for embedding, so exact identifiers (CRD names, error
+	// codes, flags) and any synonyms an expander injected both match.
+	// The indexer owns and caches this index, rebuilding it only when
+	// chunks have actually changed since the last query. BM25Index doesn't
+	// know about filter, so apply it after scoring.
+	bm25Scores := r.indexer.BM25().Score(expandedQuery)
+	if !filter.isZero() {
+		filtered := make([]ChunkScore, 0, len(bm25Scores))
+		for _, cs := range bm25Scores {
+			if filter.matches(cs.Chunk) {
+				filtered = append(filtered, cs)
+			}
+		}
+		bm25Scores = filtered
+	}
 
-	// Calculate scores with hybrid approach (semantic + BM25)
-	var scores []ChunkScore
-	for _, chunk := range allChunks {
-		// Semantic similarity
-		semanticScore := cosineSimilarity(queryEmbedding, chunk.Embedding) * 0.8
+	// Fuse the two rankings with reciprocal rank fusion rather than a fixed
+	// weighted sum, so neither ranking signal needs its score normalized
+	// onto the other's scale.
+	fused := reciprocalRankFusion(denseScores, bm25Scores)
 
-		// BM25 score for keyword matching
-		bm25Score := calculateBM25Score(query, chunk) * 0.2
+	return fused, queryEmbedding, nil
+}
 
-		// Combined score
-		totalScore := semanticScore + bm25Score
+// RetrieveRelevantChunks returns the topK chunks by raw hybrid (dense+BM25)
+// score over the whole corpus. Existing callers are unaffected by
+// RetrieveRelevantChunksMMR/RetrieveRelevantChunksWithFilter: this remains
+// the plain-score, unfiltered path.
+func (r *Retriever) RetrieveRelevantChunks(query string, topK int) ([]string, error) {
+	fused, _, err := r.rankedCandidates(query, Filter{})
+	if err != nil {
+		return nil, err
+	}
 
-		scores = append(scores, ChunkScore{
-			Chunk: chunk,
-			Score: totalScore,
+	var result []string
+	for i := 0; i < topK && i < len(fused); i++ {
+		result = append(result, fused[i].Chunk.Content)
+
+		preview := fused[i].Chunk.Content
+		if len(preview) > 100 {
+			preview = preview[:100] + "..."
+		}
+		logger.Info("Retrieved chunk", Fields{
+			"rank":    i + 1,
+			"score":   fused[i].Score,
+			"name":    fused[i].Chunk.DocID,
+			"preview": preview,
 		})
 	}
 
-	// Sort by score (descending)
-	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].Score > scores[j].Score
-	})
+	return result, nil
+}
+
+// RetrieveRelevantChunksWithFilter behaves like RetrieveRelevantChunks, but
+// scopes both the dense and BM25 rankings to chunks matching filter before
+// fusing them -- e.g. Filter{DocID: "incident-142.txt"} to search within a
+// single source document instead of the whole corpus.
+func (r *Retriever) RetrieveRelevantChunksWithFilter(query string, topK int, filter Filter) ([]string, error) {
+	fused, _, err := r.rankedCandidates(query, filter)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get top K chunks
 	var result []string
-	fmt.Println("\nTop retrieved chunks:")
-	fmt.Println("---------------------")
-	for i := 0; i < topK && i < len(scores); i++ {
-		result = append(result, scores[i].Chunk.Content)
-		fmt.Printf("Score: %.4f, Document: %s\n", scores[i].Score, scores[i].Chunk.DocID)
-		// Print a preview of the chunk content (first 100 chars)
-		preview := scores[i].Chunk.Content
+	for i := 0; i < topK && i < len(fused); i++ {
+		result = append(result, fused[i].Chunk.Content)
+	}
+	return result, nil
+}
+
+// RetrieveRelevantChunksMMR returns topK chunks selected by Maximal Marginal
+// Relevance instead of raw hybrid score: it first fetches an over-sampled
+// candidate set of fetchK chunks ranked by the usual hybrid score, then
+// greedily builds the result by repeatedly picking the candidate that
+// maximizes lambda*sim(query, c) - (1-lambda)*max(sim(c, s) for s already
+// selected). This diversifies the result against near-duplicate chunks
+// from the same document dominating topK, at the cost of some raw
+// relevance -- lambda trades that off, from 0 (pure diversity) to 1 (pure
+// relevance); ~0.5 is a reasonable default.
+func (r *Retriever) RetrieveRelevantChunksMMR(query string, topK int, lambda float32, fetchK int) ([]string, error) {
+	fused, queryEmbedding, err := r.rankedCandidates(query, Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if fetchK > len(fused) {
+		fetchK = len(fused)
+	}
+	candidates := fused[:fetchK]
+
+	selected := mmrSelect(candidates, queryEmbedding, topK, lambda)
+
+	result := make([]string, 0, len(selected))
+	for i, cs := range selected {
+		result = append(result, cs.Chunk.Content)
+
+		preview := cs.Chunk.Content
 		if len(preview) > 100 {
 			preview = preview[:100] + "..."
 		}
-		fmt.Printf("Preview: %s\n\n", preview)
+		logger.Info("Retrieved chunk (MMR)", Fields{
+			"rank":    i + 1,
+			"score":   cs.Score,
+			"name":    cs.Chunk.DocID,
+			"preview": preview,
+		})
 	}
-	fmt.Println("---------------------")
 
 	return result, nil
 }
 
+// mmrSelect greedily selects up to topK candidates by Maximal Marginal
+// Relevance. Candidate embeddings are read directly off *Chunk (stored by
+// the indexer already), so no embeddings are recomputed here.
+func mmrSelect(candidates []ChunkScore, queryEmbedding []float32, topK int, lambda float32) []ChunkScore {
+	if len(candidates) == 0 || topK <= 0 {
+		return nil
+	}
+
+	remaining := make([]*ChunkScore, len(candidates))
+	for i := range candidates {
+		remaining[i] = &candidates[i]
+	}
+
+	// Seed with the highest-scoring candidate (remaining is already sorted
+	// by fused score, descending, since it's a prefix of rankedCandidates'
+	// output).
+	selected := []ChunkScore{*remaining[0]}
+	remaining = remaining[1:]
+
+	for len(selected) < topK && len(remaining) > 0 {
+		bestIdx := -1
+		var bestScore float32
+
+		for i, c := range remaining {
+			relevance := cosineSimilarity(queryEmbedding, c.Chunk.Embedding)
+
+			var maxSimToSelected float32
+			for _, s := range selected {
+				if sim := cosineSimilarity(c.Chunk.Embedding, s.Chunk.Embedding); sim > maxSimToSelected {
+					maxSimToSelected = sim
+				}
+			}
+
+			mmrScore := lambda*relevance - (1-lambda)*maxSimToSelected
+			if bestIdx == -1 || mmrScore > bestScore {
+				bestIdx = i
+				bestScore = mmrScore
+			}
+		}
+
+		selected = append(selected, *remaining[bestIdx])
+		remaining = append(remaining[:bestIdx], remaining[bestIdx+1:]...)
+	}
+
+	return selected
+}
+
+// reciprocalRankFusion combines two independently-ranked score lists over
+// the same chunk set into one ranking: score = sum of 1/(rrfK + rank) across
+// the lists a chunk appears in, rank being its 1-indexed position within
+// that list after sorting by score descending.
+func reciprocalRankFusion(lists ...[]ChunkScore) []ChunkScore {
+	fusedScore := make(map[string]float32)
+	chunkByID := make(map[string]*Chunk)
+
+	for _, list := range lists {
+		ranked := make([]ChunkScore, len(list))
+		copy(ranked, list)
+		sort.Slice(ranked, func(i, j int) bool {
+			return ranked[i].Score > ranked[j].Score
+		})
+
+		for rank, cs := range ranked {
+			chunkByID[cs.Chunk.ID] = cs.Chunk
+			fusedScore[cs.Chunk.ID] += 1.0 / float32(rrfK+rank+1)
+		}
+	}
+
+	fused := make([]ChunkScore, 0, len(fusedScore))
+	for id, score := range fusedScore {
+		fused = append(fused, ChunkScore{Chunk: chunkByID[id], Score: score})
+	}
+
+	sort.Slice(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+
+	return fused
+}
+
 // cosineSimilarity calculates the cosine similarity between two vectors
 func cosineSimilarity(a, b []float32) float32 {
 	var dotProduct float32
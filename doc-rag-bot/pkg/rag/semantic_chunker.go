@@ -0,0 +1,130 @@
+package rag
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// paragraphBreakRegex splits text into paragraphs on blank lines, which also
+// separates markdown headings from surrounding prose since headings are
+// conventionally followed by a blank line.
+var paragraphBreakRegex = regexp.MustCompile(`\n\s*\n`)
+
+// paragraphSegments splits content into paragraph-level segments, keeping
+// fenced code blocks and YAML block scalars atomic exactly like
+// segmentDocument does for sentence-level segmentation.
+func paragraphSegments(content string) []segment {
+	atomicSpans := findAtomicSpans(content)
+
+	var segments []segment
+	cursor := 0
+	for _, span := range atomicSpans {
+		if span[0] > cursor {
+			segments = append(segments, splitParagraphs(content, cursor, span[0])...)
+		}
+		segments = append(segments, segment{
+			text:   content[span[0]:span[1]],
+			start:  span[0],
+			end:    span[1],
+			atomic: true,
+		})
+		cursor = span[1]
+	}
+	if cursor < len(content) {
+		segments = append(segments, splitParagraphs(content, cursor, len(content))...)
+	}
+
+	return segments
+}
+
+// splitParagraphs splits content[start:end] into paragraph segments on
+// blank lines.
+func splitParagraphs(content string, start, end int) []segment {
+	text := content[start:end]
+
+	var segments []segment
+	cursor := 0
+	for _, br := range paragraphBreakRegex.FindAllStringIndex(text, -1) {
+		if para := strings.TrimSpace(text[cursor:br[0]]); para != "" {
+			offset := strings.Index(text[cursor:br[0]], para)
+			segStart := start + cursor + offset
+			segments = append(segments, segment{text: para, start: segStart, end: segStart + len(para)})
+		}
+		cursor = br[1]
+	}
+	if para := strings.TrimSpace(text[cursor:]); para != "" {
+		offset := strings.Index(text[cursor:], para)
+		segStart := start + cursor + offset
+		segments = append(segments, segment{text: para, start: segStart, end: segStart + len(para)})
+	}
+
+	return segments
+}
+
+// chunkDocumentSemantic splits doc into chunks by greedily merging adjacent
+// paragraphs whose embeddings stay cosine-similar above
+// i.semanticSimilarityThreshold, capping each chunk at i.tokenBudget.
+// Fenced code blocks and YAML block scalars are never merged into a
+// neighboring paragraph, matching the hard boundary segmentDocument gives
+// them.
+func (i *Indexer) chunkDocumentSemantic(doc *Document) ([]Chunk, error) {
+	segments := paragraphSegments(doc.Content)
+	if len(segments) == 0 {
+		return nil, nil
+	}
+
+	embeddings := make([][]float32, len(segments))
+	for idx, seg := range segments {
+		embedding, err := i.embedder.GetEmbedding(seg.text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed paragraph %d of %s: %w", idx, doc.ID, err)
+		}
+		embeddings[idx] = embedding
+	}
+
+	var chunks []Chunk
+	current := []segment{segments[0]}
+	currentTokens := estimateTokens(segments[0].text)
+	currentCentroid := embeddings[0]
+
+	flush := func() {
+		chunks = append(chunks, buildChunk(current, doc.ID, len(chunks)))
+	}
+
+	for idx := 1; idx < len(segments); idx++ {
+		seg := segments[idx]
+		segTokens := estimateTokens(seg.text)
+		similarity := cosineSimilarity(currentCentroid, embeddings[idx])
+
+		hardBoundary := current[len(current)-1].atomic || seg.atomic
+		fitsBudget := currentTokens+segTokens <= i.tokenBudget
+		similarEnough := float64(similarity) >= i.semanticSimilarityThreshold
+
+		if hardBoundary || !fitsBudget || !similarEnough {
+			flush()
+			current = []segment{seg}
+			currentTokens = segTokens
+			currentCentroid = embeddings[idx]
+			continue
+		}
+
+		current = append(current, seg)
+		currentTokens += segTokens
+		currentCentroid = averageEmbedding(currentCentroid, embeddings[idx], len(current))
+	}
+	flush()
+
+	return chunks, nil
+}
+
+// averageEmbedding folds newVec into a running mean of n vectors (newVec
+// being the nth), so a merged chunk's centroid reflects all its paragraphs
+// rather than drifting toward whichever was merged in most recently.
+func averageEmbedding(mean, newVec []float32, n int) []float32 {
+	out := make([]float32, len(mean))
+	for i := range mean {
+		out[i] = mean[i] + (newVec[i]-mean[i])/float32(n)
+	}
+	return out
+}
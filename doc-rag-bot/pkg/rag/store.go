@@ -0,0 +1,126 @@
+package rag
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// indexSnapshot is the on-disk gob encoding of an Indexer's state. Chunks is
+// rebuilt from Documents on Load rather than encoded separately, since it's
+// always derivable from Documents[*].Chunks. BM25DocFreq/BM25AvgDocLength/
+// BM25NumDocs are the corpus-level BM25 stats, persisted so Load doesn't
+// need to rescan every chunk to recompute them.
+type indexSnapshot struct {
+	Documents        map[string]*Document
+	BM25DocFreq      map[string]int
+	BM25AvgDocLength float64
+	BM25NumDocs      int
+}
+
+// Save persists the indexer's documents and chunk embeddings to path as a
+// gob file, so the next run can Load them instead of re-embedding every
+// file from scratch. It writes to a temp file and renames into place so a
+// crash mid-save can't leave a truncated index behind.
+func (i *Indexer) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".rag-index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below succeeds
+
+	bm25 := i.BM25()
+	snapshot := indexSnapshot{
+		Documents:        i.Documents,
+		BM25DocFreq:      bm25.docFreq,
+		BM25AvgDocLength: bm25.avgDocLength,
+		BM25NumDocs:      bm25.numDocs,
+	}
+	if err := gob.NewEncoder(tmp).Encode(snapshot); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp index file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save index to %s: %w", path, err)
+	}
+
+	// The fresh snapshot above now covers every mutation the journal was
+	// keeping for crash recovery; truncate it so the next Load doesn't
+	// replay already-saved mutations on top of themselves.
+	if err := i.truncateJournal(); err != nil {
+		return err
+	}
+
+	logger.Info("Saved index", Fields{"path": path, "documents": len(i.Documents)})
+	return nil
+}
+
+// Load replaces the indexer's documents and chunks with those persisted at
+// path by a previous Save, then replays path's WAL journal (see journal.go)
+// on top -- any AddDocument/UpdateDocument/RemoveDocument call that was
+// durably journaled but never made it into a Save snapshot, e.g. because the
+// process crashed in between. It's not an error for path (or its journal)
+// not to exist yet -- that just means this is the first run, and
+// IndexDirectory will build the index from scratch. If any journal entries
+// were replayed, Load saves a fresh snapshot to path, which truncates the
+// journal. Once loaded, the journal is left open so subsequent
+// Add/Update/RemoveDocument calls keep recording themselves to it.
+func (i *Indexer) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to open index file: %w", err)
+		}
+	} else {
+		defer f.Close()
+
+		var snapshot indexSnapshot
+		if err := gob.NewDecoder(f).Decode(&snapshot); err != nil {
+			return fmt.Errorf("failed to decode index file: %w", err)
+		}
+
+		i.Documents = snapshot.Documents
+		i.Chunks = make(map[string]*Chunk)
+		for _, doc := range i.Documents {
+			for idx := range doc.Chunks {
+				chunk := &doc.Chunks[idx]
+				i.Chunks[chunk.ID] = chunk
+				i.store.Upsert(chunk)
+			}
+		}
+
+		if snapshot.BM25DocFreq != nil {
+			i.bm25 = newBM25IndexFromStats(i.GetAllChunks(), snapshot.BM25DocFreq, snapshot.BM25AvgDocLength, snapshot.BM25NumDocs)
+			i.bm25Dirty = false
+		} else {
+			// Loading a snapshot saved before BM25 stats were persisted.
+			i.bm25Dirty = true
+		}
+	}
+
+	walPath := journalPath(path)
+	replayed, err := i.replayJournal(walPath)
+	if err != nil {
+		return err
+	}
+	if err := i.openJournal(walPath); err != nil {
+		return err
+	}
+	if replayed > 0 {
+		// Fold the replayed entries into a fresh snapshot and truncate the
+		// journal: appending to it with a new gob.Encoder would start a
+		// second gob stream, which the next replay can't decode.
+		if err := i.Save(path); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Loaded index", Fields{"path": path, "documents": len(i.Documents), "journalReplayed": replayed})
+	return nil
+}
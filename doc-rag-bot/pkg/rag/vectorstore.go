@@ -0,0 +1,112 @@
+package rag
+
+import (
+	"sort"
+	"sync"
+)
+
+// Filter scopes a VectorStore Query to a subset of the corpus. The
+// zero-value Filter is unconstrained -- every field left unset matches
+// everything. DocID maps directly onto Chunk.DocID (the indexed file's
+// base name), which doubles as "source path" for this indexer's
+// file-at-a-time indexing model. Metadata matches against Chunk.Metadata
+// (e.g. a reconcile trace's reconcileId/controller/namespace/name/status --
+// see IngestReconcileTrace): every key in Metadata must be present and
+// equal on the chunk.
+type Filter struct {
+	DocID    string
+	Metadata map[string]string
+}
+
+// matches reports whether chunk satisfies every constraint set on f.
+func (f Filter) matches(chunk *Chunk) bool {
+	if f.DocID != "" && chunk.DocID != f.DocID {
+		return false
+	}
+	for key, value := range f.Metadata {
+		if chunk.Metadata[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero reports whether f has no constraints set, i.e. matches every
+// chunk. Filter can't use == comparison once it holds a map field.
+func (f Filter) isZero() bool {
+	return f.DocID == "" && len(f.Metadata) == 0
+}
+
+// VectorStore holds chunk embeddings and answers nearest-neighbor queries
+// over them, so Retriever doesn't have to walk every chunk in Go on every
+// call. Indexer writes to a VectorStore as it creates/evicts chunks;
+// Retriever reads from one via Query.
+type VectorStore interface {
+	// Upsert indexes or re-indexes chunk under its own ID and embedding.
+	Upsert(chunk *Chunk) error
+	// Delete removes the chunk with the given ID, if present.
+	Delete(id string) error
+	// Query returns the topK chunks matching filter ranked by cosine
+	// similarity to embedding, descending. topK <= 0 means no limit.
+	Query(embedding []float32, topK int, filter Filter) ([]ChunkScore, error)
+	// Persist writes the store's index to path.
+	Persist(path string) error
+	// Load replaces the store's contents with what's persisted at path. It
+	// is not an error for path not to exist yet.
+	Load(path string) error
+}
+
+// MemoryVectorStore is the straightforward VectorStore: an unindexed map of
+// chunks, scored by a linear scan on every Query. It's exact (no
+// approximation error) and simplest to reason about, at the cost of
+// scaling linearly with corpus size -- the right choice for the REPL's
+// typical few-hundred-chunk corpora, and the default for NewIndexer.
+type MemoryVectorStore struct {
+	mu     sync.RWMutex
+	chunks map[string]*Chunk
+}
+
+// NewMemoryVectorStore creates an empty MemoryVectorStore.
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{chunks: make(map[string]*Chunk)}
+}
+
+func (s *MemoryVectorStore) Upsert(chunk *Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks[chunk.ID] = chunk
+	return nil
+}
+
+func (s *MemoryVectorStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chunks, id)
+	return nil
+}
+
+func (s *MemoryVectorStore) Query(embedding []float32, topK int, filter Filter) ([]ChunkScore, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := make([]ChunkScore, 0, len(s.chunks))
+	for _, chunk := range s.chunks {
+		if !filter.matches(chunk) {
+			continue
+		}
+		scores = append(scores, ChunkScore{Chunk: chunk, Score: cosineSimilarity(embedding, chunk.Embedding)})
+	}
+
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	if topK > 0 && len(scores) > topK {
+		scores = scores[:topK]
+	}
+	return scores, nil
+}
+
+// Persist/Load are no-ops for MemoryVectorStore: Indexer.Save/Load already
+// persist every chunk (with its embedding) via indexSnapshot, and Indexer
+// re-Upserts each chunk into its VectorStore on Load, so there's nothing
+// for the store itself to separately own on disk.
+func (s *MemoryVectorStore) Persist(path string) error { return nil }
+func (s *MemoryVectorStore) Load(path string) error    { return nil }
@@ -1,16 +1,35 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"golang.org/x/sync/errgroup"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/api"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/cluster"
 	"github.com/yourusername/k8s-llm-analyzer/pkg/controller"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/k8s"
 	"github.com/yourusername/k8s-llm-analyzer/pkg/llm"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/cache"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/logging"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/support"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/watch"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -24,27 +43,108 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "supportbundle" {
+		runSupportBundleCLI(os.Args[2:])
+		return
+	}
+
 	var (
 		namespace        string
-		ollamaURL        string
-		ollamaModel      string
+		llmAdapter       string
+		llmBaseURL       string
+		llmModel         string
+		llmAPIKey        string
 		analysisQuestion string
+		kubeconfigDir    string
+		alertWebhookURL  string
+		lokiURL          string
+		offline          bool
+		rcaPath          string
+		llmLocalBinary   string
+		llmLocalModel    string
+		llmDebug         bool
+		watchTransitions bool
+		watchWebhookURL  string
+		watchSlackURL    string
+		cacheDir         string
+		cacheTTL         time.Duration
+		llmCostPer1K     float64
+		apiAddr          string
+		operatorNS       string
+		controlPlaneNS   string
+		fleetNamespaces  string
 	)
 
 	flag.StringVar(&namespace, "namespace", "", "Namespace to watch for resources. Leave empty to watch all namespaces.")
-	flag.StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "Ollama API URL")
-	flag.StringVar(&ollamaModel, "ollama-model", "qwen2.5", "Ollama model to use")
+	flag.StringVar(&llmAdapter, "llm-adapter", "ollama", "LLM backend adapter to use (ollama, openai, llamacpp, bedrock, vllm, anthropic, local)")
+	flag.StringVar(&llmBaseURL, "llm-base-url", "http://localhost:11434", "Base URL of the LLM backend")
+	flag.StringVar(&llmModel, "llm-model", "qwen2.5", "Model name to use")
+	flag.StringVar(&llmAPIKey, "llm-api-key", "", "API key for the LLM backend, if required")
+	flag.StringVar(&llmLocalBinary, "llm-local-binary", "", "Path to a llama.cpp CLI binary, used only when --llm-adapter=local.")
+	flag.StringVar(&llmLocalModel, "llm-local-model-path", "", "Path to an on-disk GGUF model, used only when --llm-adapter=local.")
+	flag.BoolVar(&llmDebug, "llm-debug", false, "Log every LLM adapter's request and response bodies.")
 	flag.StringVar(&analysisQuestion, "analysis-question", "Analyze these resources status and conditions.",
 		"Question to ask the LLM about the resources")
+	flag.StringVar(&kubeconfigDir, "kubeconfig-dir", "", "Directory of kubeconfigs for remote management clusters to watch. "+
+		"Leave empty to watch only the local cluster.")
+	flag.StringVar(&alertWebhookURL, "alert-webhook-url", "", "Alertmanager-compatible webhook URL to POST LLM findings to. Leave empty to disable.")
+	flag.StringVar(&lokiURL, "loki-url", "", "Loki push API URL (e.g. http://loki:3100/loki/api/v1/push) to additionally ship logs to. Leave empty to disable.")
+	flag.BoolVar(&offline, "offline", false, "Run a single offline analysis pass over a captured RCA bundle instead of watching a live cluster. Requires --rca-path.")
+	flag.StringVar(&rcaPath, "rca-path", "", "Directory of captured HostedCluster/NodePool manifests (YAML or JSON) to analyze when --offline is set.")
+	flag.BoolVar(&watchTransitions, "watch-transitions", false, "Watch the local cluster's HostedCluster/NodePool condition transitions in real time and explain Degraded/UserActionRequired ones via the LLM.")
+	flag.StringVar(&watchWebhookURL, "watch-sink-webhook-url", "", "Webhook URL to POST explained condition transitions to. Leave empty to disable.")
+	flag.StringVar(&watchSlackURL, "watch-sink-slack-url", "", "Slack incoming-webhook URL to post explained condition transitions to. Leave empty to disable.")
+	flag.StringVar(&cacheDir, "cache-dir", "", "Directory to cache LLM analysis responses in, keyed by a hash of the model/options/normalized resource data/question. Leave empty to disable caching.")
+	flag.DurationVar(&cacheTTL, "cache-ttl", time.Hour, "How long a cached LLM analysis response stays valid. Only used when --cache-dir is set.")
+	flag.Float64Var(&llmCostPer1K, "llm-cost-per-1k-tokens", 0, "USD cost per 1,000 tokens for the selected backend, used to report estimated spend. Leave 0 for free/local backends.")
+	flag.StringVar(&apiAddr, "api-addr", "", "Address to serve the per-cluster view and support-bundle HTTP API on (e.g. :8081). Leave empty to disable.")
+	flag.StringVar(&operatorNS, "operator-namespace", "hypershift", "Namespace the hypershift-operator runs in, for --api-addr's support-bundle endpoint.")
+	flag.StringVar(&controlPlaneNS, "control-plane-namespace", "", "Control-plane namespace to collect pod logs from for --api-addr's support-bundle endpoint. Leave empty to skip.")
+	flag.StringVar(&fleetNamespaces, "fleet-namespaces", "", "Comma-separated namespaces for --api-addr's /api/fleet endpoint to aggregate across. Leave empty to use --namespace only.")
 
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	zapOpts := []zap.Opts{zap.UseFlagOptions(&opts)}
+	if lokiURL != "" {
+		sink := logging.NewLokiSink(lokiURL, map[string]string{"app": "k8s-llm-analyzer", "component": "hcp-agent"})
+		zapOpts = append(zapOpts, zap.WriteTo(io.MultiWriter(os.Stderr, sink)))
+	}
+	ctrl.SetLogger(zap.New(zapOpts...))
+
+	// Create the LLM analyzer for the selected backend
+	analyzer, err := llm.NewAnalyzer(llm.Config{
+		Adapter:         llm.AdapterName(llmAdapter),
+		BaseURL:         llmBaseURL,
+		Model:           llmModel,
+		APIKey:          llmAPIKey,
+		LocalBinaryPath: llmLocalBinary,
+		LocalModelPath:  llmLocalModel,
+		Debug:           llmDebug,
+		CostPer1KTokens: llmCostPer1K,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create llm analyzer", "adapter", llmAdapter)
+		os.Exit(1)
+	}
+
+	if cacheDir != "" {
+		analyzer = cache.New(analyzer, cache.NewFileStore(cacheDir, cacheTTL), llmModel, llmCostPer1K)
+	}
+
+	if offline {
+		if rcaPath == "" {
+			setupLog.Error(nil, "--rca-path is required when --offline is set")
+			os.Exit(1)
+		}
+		runOffline(rcaPath, namespace, analyzer, analysisQuestion)
+		return
+	}
+
+	localMgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:         scheme,
 		LeaderElection: false,
 	})
@@ -53,23 +153,228 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create Ollama client
-	ollamaClient := llm.NewOllamaClient(ollamaURL, ollamaModel)
-
-	// Setup the controller
-	if err = controller.NewReconciler(
-		mgr.GetClient(),
-		ollamaClient,
+	reconciler := controller.NewReconciler(
+		localMgr.GetClient(),
+		analyzer,
 		analysisQuestion,
 		ctrl.Log.WithName("controllers").WithName("HyperShift"),
-	).SetupWithManager(mgr); err != nil {
+		localMgr.GetEventRecorderFor("hcp-agent"),
+		alertWebhookURL,
+	)
+	if err := reconciler.SetupWithManager(localMgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HyperShift")
 		os.Exit(1)
 	}
 
-	setupLog.Info("starting manager", "namespace", namespace)
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	// Registry of additional remote management clusters this agent fans out
+	// to, on top of the local manager started above.
+	registry := cluster.NewClusterRegistry()
+	if kubeconfigDir != "" {
+		if err := registry.LoadKubeconfigDir(kubeconfigDir); err != nil {
+			setupLog.Error(err, "unable to load remote cluster kubeconfigs", "kubeconfigDir", kubeconfigDir)
+			os.Exit(1)
+		}
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	group, ctx := errgroup.WithContext(ctx)
+
+	group.Go(func() error {
+		setupLog.Info("starting manager", "cluster", "local", "namespace", namespace)
+		return localMgr.Start(ctx)
+	})
+
+	// Watch-mode only covers the local cluster's cache; unlike the
+	// reconciler (set up per remote cluster below), it does not fan out
+	// across registry's remote management clusters. watcher is
+	// declared outside the if so --api-addr's support-bundle endpoint can
+	// fold its recent events in when --watch-transitions is also set, and
+	// leave them out (a nil RecentEventsProvider) otherwise.
+	var watcher *watch.Watcher
+	if watchTransitions {
+		sinks := []watch.Sink{watch.StdoutSink{}}
+		if watchWebhookURL != "" {
+			sinks = append(sinks, watch.NewWebhookSink(watchWebhookURL))
+		}
+		if watchSlackURL != "" {
+			sinks = append(sinks, watch.NewSlackSink(watchSlackURL))
+		}
+
+		watcher = watch.NewWatcher(ctrl.Log.WithName("watch"))
+		gvks := map[string]schema.GroupVersionKind{
+			"HostedCluster": {Group: "hypershift.openshift.io", Version: "v1beta1", Kind: "HostedCluster"},
+			"NodePool":      {Group: "hypershift.openshift.io", Version: "v1beta1", Kind: "NodePool"},
+		}
+
+		group.Go(func() error {
+			return watcher.Start(ctx, localMgr.GetCache(), gvks)
+		})
+		group.Go(func() error {
+			watch.Run(ctx, watcher.Events(), analyzer.Analyze, sinks, ctrl.Log.WithName("watch"))
+			return nil
+		})
+	}
+
+	if apiAddr != "" {
+		localClient, err := k8s.NewClient()
+		if err != nil {
+			setupLog.Error(err, "unable to create k8s client for api server")
+			os.Exit(1)
+		}
+
+		specs := []support.PodLogSpec{{Namespace: operatorNS}}
+		if controlPlaneNS != "" {
+			specs = append(specs, support.PodLogSpec{Namespace: controlPlaneNS})
+		}
+
+		var recentEvents api.RecentEventsProvider
+		if watcher != nil {
+			recentEvents = watcher
+		}
+
+		var fleetNS []string
+		if fleetNamespaces != "" {
+			fleetNS = strings.Split(fleetNamespaces, ",")
+		}
+
+		apiServer := api.NewServer(registry, localClient, analyzer, recentEvents, namespace, analysisQuestion, specs, fleetNS, ctrl.Log.WithName("api"))
+		httpServer := &http.Server{Addr: apiAddr, Handler: apiServer.Handler()}
+
+		group.Go(func() error {
+			setupLog.Info("starting api server", "addr", apiAddr)
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				return fmt.Errorf("api server failed: %w", err)
+			}
+			return nil
+		})
+		group.Go(func() error {
+			<-ctx.Done()
+			return httpServer.Shutdown(context.Background())
+		})
+	}
+
+	for name, remote := range registry.Clusters() {
+		name, remote := name, remote
+		remoteMgr, err := ctrl.NewManager(remote.Config, ctrl.Options{
+			Scheme:         scheme,
+			LeaderElection: false,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to start manager for remote cluster", "cluster", name)
+			os.Exit(1)
+		}
+
+		if err := reconciler.ForCluster(name, remoteMgr.GetClient(), remoteMgr.GetEventRecorderFor("hcp-agent")).SetupWithManager(remoteMgr); err != nil {
+			setupLog.Error(err, "unable to create controller for remote cluster", "cluster", name)
+			os.Exit(1)
+		}
+
+		group.Go(func() error {
+			setupLog.Info("starting manager", "cluster", name, "namespace", namespace)
+			return remoteMgr.Start(ctx)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// runOffline loads the HostedCluster/NodePool manifests captured under
+// rcaPath into an in-memory fake client, then runs a single Reconcile pass
+// against them, so the exact same analysis logic that watches a live
+// cluster can instead be pointed at a support bundle or RCA snapshot.
+func runOffline(rcaPath, namespace string, analyzer llm.Analyzer, analysisQuestion string) {
+	items, err := k8s.LoadDirectory(rcaPath)
+	if err != nil {
+		setupLog.Error(err, "failed to load RCA bundle", "path", rcaPath)
+		os.Exit(1)
+	}
+
+	objs := make([]client.Object, len(items))
+	for i := range items {
+		objs[i] = &items[i]
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	reconciler := controller.NewReconciler(
+		fakeClient,
+		analyzer,
+		analysisQuestion,
+		ctrl.Log.WithName("controllers").WithName("HyperShift"),
+		nil,
+		"",
+	)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		setupLog.Error(err, "offline analysis failed", "path", rcaPath)
+		os.Exit(1)
+	}
+}
+
+// runSupportBundleCLI implements `hcp-agent supportbundle`: it collects a
+// live HostedCluster/NodePool dump, hypershift-operator and control-plane
+// pod logs, and an LLM analysis into a single zip archive that's
+// self-contained enough to reproduce the analysis later via
+// --offline/--rca-path.
+func runSupportBundleCLI(args []string) {
+	fs := flag.NewFlagSet("supportbundle", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "Namespace the HostedCluster/NodePool resources live in")
+	operatorNamespace := fs.String("operator-namespace", "hypershift", "Namespace the hypershift-operator runs in")
+	controlPlaneNamespace := fs.String("control-plane-namespace", "", "Control-plane namespace to collect pod logs from. Leave empty to skip.")
+	output := fs.String("output", "support-bundle.zip", "Path to write the resulting zip archive to")
+	llmAdapter := fs.String("llm-adapter", "ollama", "LLM backend adapter to use (ollama, openai, llamacpp, bedrock, vllm, anthropic). The local adapter is not available here: this subcommand has no local binary or model flags.")
+	llmBaseURL := fs.String("llm-base-url", "http://localhost:11434", "Base URL of the LLM backend")
+	llmModel := fs.String("llm-model", "qwen2.5", "Model name to use")
+	llmAPIKey := fs.String("llm-api-key", "", "API key for the LLM backend, if required")
+	llmDebug := fs.Bool("llm-debug", false, "Log every LLM adapter's request and response bodies.")
+	analysisQuestion := fs.String("analysis-question", "Analyze these resources status and conditions.",
+		"Question to ask the LLM about the resources")
+	fs.Parse(args)
+
+	kubeClient, err := k8s.NewClient()
+	if err != nil {
+		setupLog.Error(err, "unable to create k8s client")
+		os.Exit(1)
+	}
+
+	analyzer, err := llm.NewAnalyzer(llm.Config{
+		Adapter: llm.AdapterName(*llmAdapter),
+		BaseURL: *llmBaseURL,
+		Model:   *llmModel,
+		APIKey:  *llmAPIKey,
+		Debug:   *llmDebug,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create llm analyzer", "adapter", *llmAdapter)
+		os.Exit(1)
+	}
+
+	specs := []support.PodLogSpec{{Namespace: *operatorNamespace}}
+	if *controlPlaneNamespace != "" {
+		specs = append(specs, support.PodLogSpec{Namespace: *controlPlaneNamespace})
+	}
+
+	bundle, err := support.Collect(context.Background(), kubeClient, kubeClient, analyzer, *namespace, *analysisQuestion, specs)
+	if err != nil {
+		setupLog.Error(err, "failed to collect support bundle")
+		os.Exit(1)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		setupLog.Error(err, "failed to create output file", "path", *output)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := support.WriteZip(f, bundle); err != nil {
+		setupLog.Error(err, "failed to write support bundle")
+		os.Exit(1)
+	}
+
+	setupLog.Info("wrote support bundle", "path", *output)
+}
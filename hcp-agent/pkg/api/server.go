@@ -0,0 +1,275 @@
+// Package api exposes hcp-agent's collectors over HTTP: a per-cluster
+// resource view across every cluster.Cluster in a cluster.ClusterRegistry
+// (plus the local cluster), and an on-demand support bundle combining
+// resources, pod logs, recently observed condition transitions, and an LLM
+// analysis -- the same data runSupportBundleCLI produces, reachable without
+// shelling into the box running the agent.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/cluster"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/fleet"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/k8s"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/support"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/watch"
+)
+
+// RecentEventsProvider is implemented by *watch.Watcher; accepting the
+// interface instead of the concrete type lets Server run with
+// --watch-transitions off (a nil Watcher) without a special case at every
+// call site.
+type RecentEventsProvider interface {
+	RecentEvents() []watch.TransitionEvent
+}
+
+// Server exposes hcp-agent's collectors over HTTP. Its zero value is not
+// usable; construct one with NewServer.
+type Server struct {
+	Registry         *cluster.ClusterRegistry
+	Local            *k8s.Client
+	Analyzer         llm.Analyzer
+	Watcher          RecentEventsProvider // nil if --watch-transitions wasn't set
+	Namespace        string
+	AnalysisQuestion string
+	PodLogSpecs      []support.PodLogSpec
+	// FleetNamespaces are the namespaces /api/fleet aggregates across, in
+	// addition to Namespace. Leave nil/empty to aggregate just Namespace.
+	FleetNamespaces []string
+	Log             logr.Logger
+}
+
+// NewServer creates a Server. registry and watcher may be nil -- an empty
+// ClusterRegistry and a disabled Watcher, respectively.
+func NewServer(registry *cluster.ClusterRegistry, local *k8s.Client, analyzer llm.Analyzer, watcher RecentEventsProvider, namespace, analysisQuestion string, podLogSpecs []support.PodLogSpec, fleetNamespaces []string, log logr.Logger) *Server {
+	if registry == nil {
+		registry = cluster.NewClusterRegistry()
+	}
+	return &Server{
+		Registry:         registry,
+		Local:            local,
+		Analyzer:         analyzer,
+		Watcher:          watcher,
+		Namespace:        namespace,
+		AnalysisQuestion: analysisQuestion,
+		PodLogSpecs:      podLogSpecs,
+		FleetNamespaces:  fleetNamespaces,
+		Log:              log,
+	}
+}
+
+// Handler returns an http.Handler serving /api/clusters, /api/clusters/,
+// /api/support-bundle, and /api/fleet.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/clusters", s.handleClusters)
+	mux.HandleFunc("/api/clusters/", s.handleCluster)
+	mux.HandleFunc("/api/support-bundle", s.handleSupportBundle)
+	mux.HandleFunc("/api/fleet", s.handleFleet)
+	return mux
+}
+
+// ClusterView is one cluster's resource summary, tagged with the cluster
+// name the same way controller.Reconciler.ForCluster tags combined data
+// before sending it to the LLM (see NewReconciler's multi-cluster support).
+type ClusterView struct {
+	Name      string `json:"name"`
+	Resources string `json:"resources,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// handleClusters returns one ClusterView for "local" plus every cluster
+// registered in Registry, fetched concurrently via errgroup so one
+// slow/unreachable remote cluster doesn't hold up the rest.
+func (s *Server) handleClusters(w http.ResponseWriter, r *http.Request) {
+	views := s.collectClusterViews(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(views); err != nil {
+		s.Log.Error(err, "failed to encode cluster views")
+	}
+}
+
+// handleCluster returns the single ClusterView named by the path segment
+// after /api/clusters/, e.g. /api/clusters/east-1.
+func (s *Server) handleCluster(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path[len("/api/clusters/"):]
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	for _, view := range s.collectClusterViews(r.Context()) {
+		if view.Name == name {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(view)
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("unknown cluster %q", name), http.StatusNotFound)
+}
+
+// collectClusterViews fetches a ClusterView for "local" and every cluster in
+// Registry concurrently, returned sorted by name for a stable response.
+func (s *Server) collectClusterViews(ctx context.Context) []ClusterView {
+	type named struct {
+		name   string
+		client *k8s.Client
+	}
+	targets := []named{{name: "local", client: s.Local}}
+	for name, remote := range s.Registry.Clusters() {
+		remoteClient, err := k8s.NewClientFromConfig(remote.Config)
+		if err != nil {
+			targets = append(targets, named{name: name})
+			s.Log.Error(err, "failed to build client for cluster", "cluster", name)
+			continue
+		}
+		targets = append(targets, named{name: name, client: remoteClient})
+	}
+
+	views := make([]ClusterView, len(targets))
+	var group errgroup.Group
+	for i, target := range targets {
+		i, target := i, target
+		group.Go(func() error {
+			view := ClusterView{Name: target.name}
+			if target.client == nil {
+				view.Error = "failed to build client"
+			} else if data, err := target.client.GetAllResources(s.Namespace); err != nil {
+				view.Error = err.Error()
+			} else {
+				view.Resources = data
+			}
+			views[i] = view
+			return nil
+		})
+	}
+	group.Wait()
+
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
+
+// handleSupportBundle streams a support bundle zip -- resources, pod logs,
+// root-cause hints, an LLM analysis, and (if Watcher is set) recently
+// observed condition transitions as events.json -- built via
+// support.CollectAndStream so the response grows as each collector
+// finishes instead of only after all of them are done.
+func (s *Server) handleSupportBundle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="support-bundle.zip"`)
+
+	var events support.EventsProvider
+	if s.Watcher != nil {
+		events = func() (string, error) {
+			data, err := json.MarshalIndent(s.Watcher.RecentEvents(), "", "  ")
+			return string(data), err
+		}
+	}
+
+	if err := support.CollectAndStream(r.Context(), w, s.Local, s.Local, s.Analyzer, s.Namespace, s.AnalysisQuestion, s.PodLogSpecs, events); err != nil {
+		s.Log.Error(err, "failed to collect support bundle")
+		// The zip's local file headers may already be flushed to the
+		// client at this point, so a 500 here is best-effort: it only
+		// actually reaches the client if nothing has been written yet.
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// FleetResponse is /api/fleet's response body: the per-resource health
+// breakdown, the fleet-wide rollup, and the LLM's narrative analysis of it.
+type FleetResponse struct {
+	ClusterHealth []fleet.ClusterHealth `json:"clusterHealth"`
+	Rollup        fleet.Rollup          `json:"rollup"`
+	Analysis      string                `json:"analysis"`
+}
+
+// fleetNamespaces returns the namespaces to aggregate across: FleetNamespaces
+// if set, else just Namespace.
+func (s *Server) fleetNamespaces() []string {
+	if len(s.FleetNamespaces) > 0 {
+		return s.FleetNamespaces
+	}
+	return []string{s.Namespace}
+}
+
+// collectFleetInputs fetches a fleet.FleetInput per namespace for "local" and
+// every cluster in Registry concurrently, mirroring collectClusterViews's
+// per-cluster client-building pattern.
+func (s *Server) collectFleetInputs(ctx context.Context) []fleet.FleetInput {
+	type named struct {
+		managementCluster string
+		client            *k8s.Client
+	}
+	targets := []named{{managementCluster: "", client: s.Local}}
+	for name, remote := range s.Registry.Clusters() {
+		remoteClient, err := k8s.NewClientFromConfig(remote.Config)
+		if err != nil {
+			s.Log.Error(err, "failed to build client for cluster", "cluster", name)
+			continue
+		}
+		targets = append(targets, named{managementCluster: name, client: remoteClient})
+	}
+
+	namespaces := s.fleetNamespaces()
+	inputs := make([]fleet.FleetInput, len(targets)*len(namespaces))
+	var group errgroup.Group
+	for i, target := range targets {
+		for j, namespace := range namespaces {
+			i, j, target, namespace := i, j, target, namespace
+			group.Go(func() error {
+				idx := i*len(namespaces) + j
+				resources, err := target.client.GetResourceConditions(namespace)
+				if err != nil {
+					s.Log.Error(err, "failed to get resource conditions", "cluster", target.managementCluster, "namespace", namespace)
+					return nil
+				}
+				inputs[idx] = fleet.FleetInput{
+					ManagementCluster: target.managementCluster,
+					Namespace:         namespace,
+					Resources:         resources,
+				}
+				return nil
+			})
+		}
+	}
+	group.Wait()
+
+	return inputs
+}
+
+// handleFleet aggregates HostedCluster/NodePool conditions across every
+// namespace in FleetNamespaces and every cluster in Registry (plus local)
+// into a fleet-wide health rollup, and asks the LLM to analyze it via the
+// "fleet summary" prompt mode -- the call site fleet.Aggregate and
+// llm.Analyzer.AnalyzeFleet were built for but, until now, nothing invoked.
+func (s *Server) handleFleet(w http.ResponseWriter, r *http.Request) {
+	inputs := s.collectFleetInputs(r.Context())
+	healths, rollup := fleet.Aggregate(inputs)
+
+	rollupJSON, err := fleet.RenderJSON(rollup)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	analysis, err := s.Analyzer.AnalyzeFleet(r.Context(), rollupJSON, s.AnalysisQuestion)
+	if err != nil {
+		s.Log.Error(err, "failed to analyze fleet rollup")
+		analysis = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(FleetResponse{ClusterHealth: healths, Rollup: rollup, Analysis: analysis}); err != nil {
+		s.Log.Error(err, "failed to encode fleet response")
+	}
+}
@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterNameLabel is the label used to identify a Secret as carrying a
+// remote management cluster's kubeconfig, matching the convention used by
+// cluster-api bootstrap providers.
+const clusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+// Cluster is a single remote management cluster discovered by a
+// ClusterRegistry.
+type Cluster struct {
+	// Name tags data gathered from this cluster before it is sent to the LLM.
+	Name   string
+	Config *rest.Config
+}
+
+// ClusterRegistry discovers the set of management clusters a Reconciler
+// should watch, either from a directory of kubeconfig files or from
+// Opaque Secrets labeled cluster.x-k8s.io/cluster-name in a local cluster.
+type ClusterRegistry struct {
+	clusters map[string]*Cluster
+}
+
+// NewClusterRegistry creates an empty ClusterRegistry.
+func NewClusterRegistry() *ClusterRegistry {
+	return &ClusterRegistry{clusters: make(map[string]*Cluster)}
+}
+
+// LoadKubeconfigDir registers one cluster per kubeconfig file found directly
+// under dir, naming each cluster after the file's base name.
+func (r *ClusterRegistry) LoadKubeconfigDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig dir %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		cfg, err := clientcmd.BuildConfigFromFlags("", path)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		r.clusters[name] = &Cluster{Name: name, Config: cfg}
+	}
+
+	return nil
+}
+
+// LoadFromSecrets registers one cluster per Opaque Secret labeled
+// cluster.x-k8s.io/cluster-name in namespace of the local cluster reached
+// through localClient. Each Secret is expected to carry its kubeconfig under
+// the "kubeconfig" data key, and is named after the label's value.
+func (r *ClusterRegistry) LoadFromSecrets(ctx context.Context, localClient client.Client, namespace string) error {
+	secrets := &corev1.SecretList{}
+	if err := localClient.List(ctx, secrets, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list cluster kubeconfig secrets: %w", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if secret.Type != corev1.SecretTypeOpaque {
+			continue
+		}
+		name, ok := secret.Labels[clusterNameLabel]
+		if !ok {
+			continue
+		}
+
+		kubeconfig, ok := secret.Data["kubeconfig"]
+		if !ok {
+			return fmt.Errorf("secret %s/%s is missing a kubeconfig data key", secret.Namespace, secret.Name)
+		}
+
+		cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to parse kubeconfig from secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+
+		r.clusters[name] = &Cluster{Name: name, Config: cfg}
+	}
+
+	return nil
+}
+
+// Clusters returns the set of registered clusters keyed by name.
+func (r *ClusterRegistry) Clusters() map[string]*Cluster {
+	return r.clusters
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resourceSnapshot is the optimistic-concurrency tuple cached per resource,
+// modeled on how etcd decides whether a value needs re-reading: a UID
+// detects recreation, a ResourceVersion cheaply detects "nothing changed",
+// and the remaining fields describe what changed when the ResourceVersion
+// does differ.
+type resourceSnapshot struct {
+	UID             types.UID
+	ResourceVersion string
+	Phase           string
+	Replicas        int64
+	ConditionsHash  string
+	conditions      map[string]string // condition type -> status, for diffing
+}
+
+func snapshotKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+func conditionsMapAndHash(conditions []interface{}) (map[string]string, string) {
+	byType := make(map[string]string, len(conditions))
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _, _ := unstructured.NestedString(m, "type")
+		status, _, _ := unstructured.NestedString(m, "status")
+		byType[condType] = status
+	}
+
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	normalized := make([]string, 0, len(types))
+	for _, t := range types {
+		normalized = append(normalized, t+"="+byType[t])
+	}
+	sum := sha256.Sum256([]byte(strings.Join(normalized, ",")))
+	return byType, hex.EncodeToString(sum[:])
+}
+
+// checkAndUpdate compares snap against the last snapshot cached for key,
+// unconditionally replacing it so the next call compares against the
+// latest state. It reports whether the resource changed (new, recreated,
+// or a different ResourceVersion) and, if so, a human-readable summary of
+// what changed for the LLM to reason about instead of the full resource
+// listing.
+func (r *Reconciler) checkAndUpdate(key string, snap resourceSnapshot) (changed bool, summary string) {
+	defer r.changeCache.Store(key, snap)
+
+	prevVal, ok := r.changeCache.Load(key)
+	if !ok {
+		return true, fmt.Sprintf("%s: newly observed, phase=%s replicas=%d conditions=%s",
+			key, snap.Phase, snap.Replicas, formatConditions(snap.conditions))
+	}
+
+	prev := prevVal.(resourceSnapshot)
+	if prev.UID != snap.UID {
+		return true, fmt.Sprintf("%s: recreated (uid %s -> %s)", key, prev.UID, snap.UID)
+	}
+	if prev.ResourceVersion == snap.ResourceVersion {
+		return false, ""
+	}
+
+	var changes []string
+	if prev.Phase != snap.Phase {
+		changes = append(changes, fmt.Sprintf("phase %s -> %s", prev.Phase, snap.Phase))
+	}
+	if prev.Replicas != snap.Replicas {
+		changes = append(changes, fmt.Sprintf("replicas %d -> %d", prev.Replicas, snap.Replicas))
+	}
+	if prev.ConditionsHash != snap.ConditionsHash {
+		changes = append(changes, diffConditions(prev.conditions, snap.conditions)...)
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "resourceVersion advanced with no observable field change")
+	}
+
+	return true, fmt.Sprintf("%s: %s", key, strings.Join(changes, "; "))
+}
+
+// diffConditions describes added, removed and status-changed conditions
+// between two observations of the same resource.
+func diffConditions(prev, cur map[string]string) []string {
+	var changes []string
+
+	for condType, curStatus := range cur {
+		if prevStatus, ok := prev[condType]; !ok {
+			changes = append(changes, fmt.Sprintf("condition %s added (%s)", condType, curStatus))
+		} else if prevStatus != curStatus {
+			changes = append(changes, fmt.Sprintf("condition %s: %s -> %s", condType, prevStatus, curStatus))
+		}
+	}
+	for condType := range prev {
+		if _, ok := cur[condType]; !ok {
+			changes = append(changes, fmt.Sprintf("condition %s removed", condType))
+		}
+	}
+
+	sort.Strings(changes)
+	return changes
+}
+
+func formatConditions(conditions map[string]string) string {
+	if len(conditions) == 0 {
+		return "none"
+	}
+
+	types := make([]string, 0, len(conditions))
+	for t := range conditions {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, t+"="+conditions[t])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Finding is the structured shape we ask the LLM to emit alongside its
+// prose analysis, so the agent can turn it into first-class signals
+// (Events, alerts) instead of a Println-only blob.
+type Finding struct {
+	Severity string `json:"severity"`
+	Resource string `json:"resource"` // "<Kind>/<name>", e.g. "HostedCluster/my-cluster"
+	Message  string `json:"message"`
+}
+
+// findingsBlockRegex pulls a fenced ```json ... ``` block, if any, out of an
+// LLM response so findings can be parsed without requiring the whole
+// response to be JSON.
+var findingsBlockRegex = regexp.MustCompile("(?s)```json\\s*(.*?)\\s*```")
+
+// parseFindings extracts a []Finding from an LLM analysis. It first looks
+// for a fenced JSON code block, then falls back to treating the entire
+// analysis as a JSON array, and returns an empty slice (not an error) when
+// neither parses, since most prompts still produce free-form prose today.
+func parseFindings(analysis string) []Finding {
+	candidate := analysis
+	if m := findingsBlockRegex.FindStringSubmatch(analysis); m != nil {
+		candidate = m[1]
+	}
+
+	var findings []Finding
+	if err := json.Unmarshal([]byte(candidate), &findings); err != nil {
+		return nil
+	}
+	return findings
+}
+
+// eventTypeForSeverity maps a Finding's free-form severity string to one of
+// the two event types the Kubernetes API accepts.
+func eventTypeForSeverity(severity string) string {
+	switch severity {
+	case "critical", "error", "degraded":
+		return "Warning"
+	default:
+		return "Normal"
+	}
+}
+
+// emitFindings records a Kubernetes Event for each finding on the resource it
+// names, and POSTs it to the Alertmanager-compatible webhook if configured.
+func (r *Reconciler) emitFindings(ctx context.Context, namespace string, findings []Finding) {
+	for _, finding := range findings {
+		kind, name := splitResource(finding.Resource)
+		if name == "" {
+			continue
+		}
+
+		if r.recorder != nil {
+			target := &unstructured.Unstructured{}
+			target.SetAPIVersion(hostedClusterGVK.GroupVersion().String())
+			target.SetKind(kind)
+			target.SetNamespace(namespace)
+			target.SetName(name)
+			r.recorder.Event(target, eventTypeForSeverity(finding.Severity), "LLMAnalysis", finding.Message)
+		}
+
+		if r.alertWebhookURL != "" {
+			if err := r.postAlert(ctx, namespace, finding); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "failed to post alert webhook", "resource", finding.Resource)
+			}
+		}
+	}
+}
+
+// splitResource parses a "<Kind>/<name>" finding.Resource into its parts,
+// defaulting to HostedCluster when no kind prefix is present.
+func splitResource(resource string) (kind, name string) {
+	for i := 0; i < len(resource); i++ {
+		if resource[i] == '/' {
+			return resource[:i], resource[i+1:]
+		}
+	}
+	return "HostedCluster", resource
+}
+
+// alertmanagerAlert is the minimal shape Alertmanager's /api/v2/alerts
+// endpoint expects.
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func (r *Reconciler) postAlert(ctx context.Context, namespace string, finding Finding) error {
+	alert := []alertmanagerAlert{{
+		Labels: map[string]string{
+			"alertname": "HyperShiftLLMFinding",
+			"severity":  finding.Severity,
+			"namespace": namespace,
+			"resource":  finding.Resource,
+		},
+		Annotations: map[string]string{
+			"message": finding.Message,
+		},
+	}}
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.alertWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,12 +3,18 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/yourusername/k8s-llm-analyzer/pkg/llm"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/rootcause"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/metrics"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -32,77 +38,149 @@ var (
 // Reconciler reconciles HostedCluster and NodePool resources
 type Reconciler struct {
 	client           client.Client
-	ollamaClient     *llm.OllamaClient
+	analyzer         llm.Analyzer
 	analysisQuestion string
-	lastAnalysisTime time.Time
+	// changeCache holds the last resourceSnapshot seen per resource (keyed by
+	// snapshotKey), so Reconcile can skip the LLM call entirely when nothing
+	// has changed since the last analysis. It is a *sync.Map rather than a
+	// value so ForCluster can hand a fresh cache to each cluster's clone
+	// without copying a lock.
+	changeCache *sync.Map
+	// clusterName tags data gathered by this Reconciler before it is sent to
+	// the LLM. It is empty when watching only the local management cluster.
+	clusterName string
+	// recorder emits Kubernetes Events for findings the LLM surfaces. May be
+	// nil, in which case event emission is skipped.
+	recorder record.EventRecorder
+	// alertWebhookURL, if set, receives an Alertmanager-compatible POST for
+	// every finding the LLM surfaces.
+	alertWebhookURL string
 }
 
 // NewHyperShiftReconciler creates a new reconciler for HyperShift resources
 func NewReconciler(
 	client client.Client,
-	ollamaClient *llm.OllamaClient,
+	analyzer llm.Analyzer,
 	analysisQuestion string,
 	log logr.Logger,
+	recorder record.EventRecorder,
+	alertWebhookURL string,
 ) *Reconciler {
 	return &Reconciler{
 		client:           client,
-		ollamaClient:     ollamaClient,
+		analyzer:         analyzer,
 		analysisQuestion: analysisQuestion,
-		lastAnalysisTime: time.Time{}, // Zero time
+		changeCache:      &sync.Map{},
+		recorder:         recorder,
+		alertWebhookURL:  alertWebhookURL,
 	}
 }
 
+// ForCluster returns a copy of the Reconciler that tags the data it sends to
+// the LLM with clusterName and records Events through recorder, for use
+// against a remote management cluster discovered through a
+// cluster.ClusterRegistry. It gets its own changeCache since resource
+// identity (namespace/kind/name) isn't unique across clusters.
+func (r *Reconciler) ForCluster(clusterName string, client client.Client, recorder record.EventRecorder) *Reconciler {
+	clone := *r
+	clone.client = client
+	clone.clusterName = clusterName
+	clone.recorder = recorder
+	clone.changeCache = &sync.Map{}
+	return &clone
+}
+
 // Reconcile processes HostedCluster and NodePool resources
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx).WithName("agent")
 
-	// Throttle analysis to avoid too frequent LLM calls
-	// Only analyze once every 5 minutes
-	if time.Since(r.lastAnalysisTime) < 5*time.Minute {
-		log.Info("Skipping analysis due to throttling", "lastAnalysis", r.lastAnalysisTime)
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
-	}
+	reconcileStart := time.Now()
+	defer func() {
+		metrics.ReconcileDuration.Observe(time.Since(reconcileStart).Seconds())
+	}()
 
 	log.Info("Starting reconciliation")
 
 	// Get all HostedClusters in the namespace
-	hostedClustersData, err := r.getHostedClusters(ctx, req.Namespace)
+	hostedClustersData, hostedClusterDiffs, hostedClusterConditions, err := r.getHostedClusters(ctx, req.Namespace)
 	if err != nil {
 		log.Error(err, "Failed to get HostedClusters")
 		return ctrl.Result{}, err
 	}
 
 	// Get all NodePools in the namespace
-	nodePoolsData, err := r.getNodePools(ctx, req.Namespace)
+	nodePoolsData, nodePoolDiffs, nodePoolConditions, err := r.getNodePools(ctx, req.Namespace)
 	if err != nil {
 		log.Error(err, "Failed to get NodePools")
 		return ctrl.Result{}, err
 	}
 
-	// Combine the data
-	combinedData := hostedClustersData + "\n" + nodePoolsData
-
 	// If there's no data, skip analysis
-	if combinedData == fmt.Sprintf("Found 0 HostedClusters in namespace %s:\n\nFound 0 NodePools in namespace %s:\n",
-		req.Namespace, req.Namespace) {
+	if hostedClustersData == fmt.Sprintf("Found 0 HostedClusters in namespace %s:\n", req.Namespace) &&
+		nodePoolsData == fmt.Sprintf("Found 0 NodePools in namespace %s:\n", req.Namespace) {
 		log.Info("No resources found, skipping analysis")
 		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
 	}
 
+	// Nothing changed since the last analyzed (uid, resourceVersion,
+	// conditionsHash) of every resource: skip the LLM call entirely instead
+	// of re-sending data it has already seen.
+	diffs := append(hostedClusterDiffs, nodePoolDiffs...)
+	if len(diffs) == 0 {
+		log.Info("No resource changes since last analysis, skipping LLM call")
+		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	}
+
+	// Feed the LLM just the diff rather than the full re-rendered text, so
+	// the prompt stays small even on a namespace with many resources.
+	// Alongside it, a classified readiness summary (see pkg/llm/analyzer)
+	// gives the LLM the current state of every gating condition without it
+	// having to re-derive severity from the dictionaries in buildPrompt.
+	conditions := append(hostedClusterConditions, nodePoolConditions...)
+	summaryJSON, err := analyzer.RenderJSON(analyzer.NewConditionSummarizer().Summarize(conditions))
+	if err != nil {
+		log.Error(err, "Failed to render condition summary")
+		return ctrl.Result{}, err
+	}
+
+	// Deterministic, pre-LLM root-cause hints from known bubble-up
+	// relationships between conditions (see pkg/llm/rootcause). Logged
+	// independently of the LLM call below so an operator can read them
+	// straight out of the controller logs without an LLM at all.
+	hints := rootcause.Generate(conditions)
+	hintsSection := rootcause.RenderPromptSection(hints)
+	if len(hints) > 0 {
+		log.Info("Deterministic root-cause hints", "hints", hints)
+	}
+
+	combinedData := "Current condition summary:\n" + summaryJSON +
+		"\n\n" + hintsSection +
+		"\nChanges since last analysis:\n" + strings.Join(diffs, "\n")
+	if r.clusterName != "" {
+		combinedData = fmt.Sprintf("Cluster: %s\n%s", r.clusterName, combinedData)
+	}
+
 	// Send to LLM for analysis
-	log.Info("Sending resources to LLM for analysis", "combinedData", combinedData)
+	log.Info("Sending resource changes to LLM for analysis", "combinedData", combinedData)
 
-	analysis, err := r.ollamaClient.Analyze(combinedData, r.analysisQuestion)
+	llmCallStart := time.Now()
+	analysis, err := r.analyzer.Analyze(ctx, combinedData, r.analysisQuestion)
+	metrics.LLMCallDuration.Observe(time.Since(llmCallStart).Seconds())
+	metrics.LLMTokensEstimatedTotal.Add(float64(len(combinedData)) / 4)
 	if err != nil {
+		metrics.LLMCallErrorsTotal.Inc()
 		log.Error(err, "Failed to analyze with LLM")
 		return ctrl.Result{}, err
 	}
 
-	// Update the last analysis time
-	r.lastAnalysisTime = time.Now()
-
 	// Log the analysis
-	fmt.Println("Agent analysis: ", analysis)
+	log.Info("Agent analysis", "analysis", analysis)
+
+	// Turn any structured findings the LLM emitted into Events and alerts.
+	if findings := parseFindings(analysis); len(findings) > 0 {
+		r.emitFindings(ctx, req.Namespace, findings)
+	}
+
 	// Requeue after 5 minutes
 	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
 }
@@ -124,8 +202,11 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-// getHostedClusters retrieves HostedCluster resources from the specified namespace
-func (r *Reconciler) getHostedClusters(ctx context.Context, namespace string) (string, error) {
+// getHostedClusters retrieves HostedCluster resources from the specified
+// namespace, rendering their status alongside a change summary (diffs)
+// against the last snapshot this Reconciler saw of each one, and the
+// structured conditions for the LLM's readiness summary.
+func (r *Reconciler) getHostedClusters(ctx context.Context, namespace string) (rendered string, diffs []string, resourceConditions []analyzer.ResourceConditions, err error) {
 	// Create a list object for HostedClusters
 	list := &unstructured.UnstructuredList{}
 	list.SetGroupVersionKind(schema.GroupVersionKind{
@@ -136,7 +217,7 @@ func (r *Reconciler) getHostedClusters(ctx context.Context, namespace string) (s
 
 	// List the HostedClusters
 	if err := r.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
-		return "", fmt.Errorf("failed to list HostedClusters: %w", err)
+		return "", nil, nil, fmt.Errorf("failed to list HostedClusters: %w", err)
 	}
 
 	// Format HostedCluster information as a string
@@ -155,6 +236,8 @@ func (r *Reconciler) getHostedClusters(ctx context.Context, namespace string) (s
 
 		// Get conditions
 		conditions, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+		conditionsByType, conditionsHash := conditionsMapAndHash(conditions)
+		var analyzerConditions []analyzer.Condition
 		if exists && len(conditions) > 0 {
 			result += "  • Conditions:\n"
 			for _, c := range conditions {
@@ -166,19 +249,43 @@ func (r *Reconciler) getHostedClusters(ctx context.Context, namespace string) (s
 				condType, _, _ := unstructured.NestedString(condition, "type")
 				status, _, _ := unstructured.NestedString(condition, "status")
 				reason, _, _ := unstructured.NestedString(condition, "reason")
+				message, _, _ := unstructured.NestedString(condition, "message")
 
 				result += fmt.Sprintf("    - %s: %s (%s)\n", condType, status, reason)
+				metrics.HostedClusterConditionStatus.WithLabelValues(namespace, name, "HostedCluster", condType).
+					Set(metrics.ConditionStatusValue(status))
+				analyzerConditions = append(analyzerConditions, analyzer.Condition{
+					Type: condType, Status: status, Reason: reason, Message: message,
+				})
 			}
 		}
+		resourceConditions = append(resourceConditions, analyzer.ResourceConditions{
+			Kind: "HostedCluster", Name: name, Conditions: analyzerConditions,
+		})
 
 		result += "\n"
+
+		key := snapshotKey(namespace, "HostedCluster", name)
+		changed, diff := r.checkAndUpdate(key, resourceSnapshot{
+			UID:             item.GetUID(),
+			ResourceVersion: item.GetResourceVersion(),
+			Phase:           status,
+			ConditionsHash:  conditionsHash,
+			conditions:      conditionsByType,
+		})
+		if changed {
+			diffs = append(diffs, diff)
+		}
 	}
 
-	return result, nil
+	return result, diffs, resourceConditions, nil
 }
 
-// getNodePools retrieves NodePool resources from the specified namespace
-func (r *Reconciler) getNodePools(ctx context.Context, namespace string) (string, error) {
+// getNodePools retrieves NodePool resources from the specified namespace,
+// rendering their status alongside a change summary (diffs) against the
+// last snapshot this Reconciler saw of each one, and the structured
+// conditions for the LLM's readiness summary.
+func (r *Reconciler) getNodePools(ctx context.Context, namespace string) (rendered string, diffs []string, resourceConditions []analyzer.ResourceConditions, err error) {
 	// Create a list object for NodePools
 	list := &unstructured.UnstructuredList{}
 	list.SetGroupVersionKind(schema.GroupVersionKind{
@@ -189,7 +296,7 @@ func (r *Reconciler) getNodePools(ctx context.Context, namespace string) (string
 
 	// List the NodePools
 	if err := r.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
-		return "", fmt.Errorf("failed to list NodePools: %w", err)
+		return "", nil, nil, fmt.Errorf("failed to list NodePools: %w", err)
 	}
 
 	// Format NodePool information as a string
@@ -218,6 +325,8 @@ func (r *Reconciler) getNodePools(ctx context.Context, namespace string) (string
 
 		// Get conditions
 		conditions, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+		conditionsByType, conditionsHash := conditionsMapAndHash(conditions)
+		var analyzerConditions []analyzer.Condition
 		if exists && len(conditions) > 0 {
 			result += "  • Conditions:\n"
 			for _, c := range conditions {
@@ -229,13 +338,34 @@ func (r *Reconciler) getNodePools(ctx context.Context, namespace string) (string
 				condType, _, _ := unstructured.NestedString(condition, "type")
 				status, _, _ := unstructured.NestedString(condition, "status")
 				reason, _, _ := unstructured.NestedString(condition, "reason")
+				message, _, _ := unstructured.NestedString(condition, "message")
 
 				result += fmt.Sprintf("    - %s: %s (%s)\n", condType, status, reason)
+				metrics.HostedClusterConditionStatus.WithLabelValues(namespace, name, "NodePool", condType).
+					Set(metrics.ConditionStatusValue(status))
+				analyzerConditions = append(analyzerConditions, analyzer.Condition{
+					Type: condType, Status: status, Reason: reason, Message: message,
+				})
 			}
 		}
+		resourceConditions = append(resourceConditions, analyzer.ResourceConditions{
+			Kind: "NodePool", Name: name, Conditions: analyzerConditions,
+		})
 
 		result += "\n"
+
+		key := snapshotKey(namespace, "NodePool", name)
+		changed, diff := r.checkAndUpdate(key, resourceSnapshot{
+			UID:             item.GetUID(),
+			ResourceVersion: item.GetResourceVersion(),
+			Replicas:        replicas,
+			ConditionsHash:  conditionsHash,
+			conditions:      conditionsByType,
+		})
+		if changed {
+			diffs = append(diffs, diff)
+		}
 	}
 
-	return result, nil
+	return result, diffs, resourceConditions, nil
 }
@@ -0,0 +1,165 @@
+// Package fleet aggregates HostedCluster/NodePool conditions across
+// namespaces -- and, via FleetInput.ManagementCluster, across multiple
+// management clusters reached through separate kubeconfig contexts -- into
+// a fleet-wide health rollup, the data behind the "summary including the
+// overall health of the fleet" line buildPrompt has always asked the LLM
+// for without anything actually computing it.
+package fleet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+)
+
+// FleetInput is one namespace's worth of resources from one management
+// cluster. ManagementCluster is empty for the local cluster a hcp-agent
+// instance watches directly.
+type FleetInput struct {
+	ManagementCluster string
+	Namespace         string
+	Resources         []analyzer.ResourceConditions
+}
+
+// ClusterHealth is the per-HostedCluster (or NodePool) health score used to
+// build the fleet rollup.
+type ClusterHealth struct {
+	ManagementCluster string       `json:"managementCluster,omitempty"`
+	Namespace         string       `json:"namespace"`
+	analyzer.ResourceSummary
+	// Size is the t-shirt size last computed for this resource, read from
+	// the Reason of its ClusterSizeComputed condition. Empty if the
+	// resource has no such condition (e.g. a NodePool).
+	Size string `json:"size,omitempty"`
+	// SizeTransitionPending mirrors the ClusterSizeTransitionPending
+	// condition: a size change has been decided but not yet applied.
+	SizeTransitionPending bool `json:"sizeTransitionPending,omitempty"`
+}
+
+// ConditionCount is how many resources have a given condition type
+// classified into a given bucket, used to surface the fleet's top-N
+// failing conditions.
+type ConditionCount struct {
+	Type   string         `json:"type"`
+	Bucket analyzer.Bucket `json:"bucket"`
+	Count  int            `json:"count"`
+}
+
+// Rollup is the fleet-wide health summary.
+type Rollup struct {
+	TotalResources int `json:"totalResources"`
+	// CountsByReadiness is how many resources landed in each readiness
+	// bucket.
+	CountsByReadiness map[analyzer.Bucket]int `json:"countsByReadiness"`
+	// TopFailingConditions are the most common non-Healthy conditions
+	// across the fleet, most common first.
+	TopFailingConditions []ConditionCount `json:"topFailingConditions"`
+	// SizeDistribution counts resources by t-shirt size.
+	SizeDistribution map[string]int `json:"sizeDistribution"`
+	// TransitionPendingCount is how many resources have a size transition
+	// decided but not yet applied.
+	TransitionPendingCount int `json:"transitionPendingCount"`
+	// UpgradingCount is how many resources currently have
+	// Progressing=True.
+	UpgradingCount int `json:"upgradingCount"`
+}
+
+const topFailingConditionsLimit = 10
+
+// Aggregate classifies every resource in inputs and computes the fleet-wide
+// rollup alongside the per-resource ClusterHealth it was derived from.
+func Aggregate(inputs []FleetInput) ([]ClusterHealth, Rollup) {
+	summarizer := analyzer.NewConditionSummarizer()
+
+	var healths []ClusterHealth
+	rollup := Rollup{
+		CountsByReadiness: make(map[analyzer.Bucket]int),
+		SizeDistribution:  make(map[string]int),
+	}
+	conditionCounts := make(map[string]ConditionCount)
+
+	for _, input := range inputs {
+		summaries := summarizer.Summarize(input.Resources)
+		for i, summary := range summaries {
+			res := input.Resources[i]
+			size, transitionPending, progressing := inspect(res)
+
+			healths = append(healths, ClusterHealth{
+				ManagementCluster:    input.ManagementCluster,
+				Namespace:            input.Namespace,
+				ResourceSummary:      summary,
+				Size:                 size,
+				SizeTransitionPending: transitionPending,
+			})
+
+			rollup.TotalResources++
+			rollup.CountsByReadiness[summary.Readiness]++
+			if size != "" {
+				rollup.SizeDistribution[size]++
+			}
+			if transitionPending {
+				rollup.TransitionPendingCount++
+			}
+			if progressing {
+				rollup.UpgradingCount++
+			}
+
+			for _, fc := range summary.FailingConditions {
+				key := fc.Type + "|" + string(fc.Bucket)
+				entry := conditionCounts[key]
+				entry.Type, entry.Bucket = fc.Type, fc.Bucket
+				entry.Count++
+				conditionCounts[key] = entry
+			}
+		}
+	}
+
+	rollup.TopFailingConditions = topConditions(conditionCounts)
+	return healths, rollup
+}
+
+// inspect reads the t-shirt size, transition-pending flag, and
+// upgrade-in-progress flag directly off res's raw conditions, since none of
+// that is something ConditionSummarizer's severity classification captures.
+func inspect(res analyzer.ResourceConditions) (size string, transitionPending, progressing bool) {
+	for _, c := range res.Conditions {
+		switch c.Type {
+		case "ClusterSizeComputed":
+			size = c.Reason
+		case "ClusterSizeTransitionPending":
+			transitionPending = c.Status == "True"
+		case "Progressing":
+			progressing = c.Status == "True"
+		}
+	}
+	return size, transitionPending, progressing
+}
+
+func topConditions(counts map[string]ConditionCount) []ConditionCount {
+	out := make([]ConditionCount, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Type < out[j].Type
+	})
+	if len(out) > topFailingConditionsLimit {
+		out = out[:topFailingConditionsLimit]
+	}
+	return out
+}
+
+// RenderJSON marshals rollup into the indented JSON blob injected into the
+// "fleet summary" prompt mode.
+func RenderJSON(rollup Rollup) (string, error) {
+	b, err := json.MarshalIndent(rollup, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal fleet rollup: %w", err)
+	}
+	return string(b), nil
+}
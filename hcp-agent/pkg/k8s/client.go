@@ -1,16 +1,24 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
 )
 
 // Define the GVK (Group, Version, Kind) for our custom resources
@@ -30,22 +38,31 @@ var (
 
 // Client wraps the controller-runtime client
 type Client struct {
-	client client.Client
-	scheme *runtime.Scheme
+	client    client.Client
+	scheme    *runtime.Scheme
+	clientset *kubernetes.Clientset
 }
 
 // NewClient creates a new Kubernetes client using controller-runtime
 func NewClient() (*Client, error) {
-	// Use the current context in kubeconfig
-	home := homedir.HomeDir()
-	kubeconfig := filepath.Join(home, ".kube", "config")
+	return newClientFromKubeconfig(filepath.Join(homedir.HomeDir(), ".kube", "config"))
+}
 
+func newClientFromKubeconfig(kubeconfig string) (*Client, error) {
 	// Build the config from the kubeconfig file
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
 
+	return NewClientFromConfig(config)
+}
+
+// NewClientFromConfig creates a new Client from an already-built rest.Config,
+// for callers that don't have a kubeconfig file on disk to read -- e.g.
+// api.Server building a Client per cluster.Cluster out of a
+// cluster.ClusterRegistry, which only carries a *rest.Config.
+func NewClientFromConfig(config *rest.Config) (*Client, error) {
 	// Create a new scheme
 	scheme := runtime.NewScheme()
 
@@ -69,12 +86,61 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 
+	// A typed clientset is needed alongside the controller-runtime client
+	// because fetching pod logs is a subresource operation client.Client
+	// doesn't expose.
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
 	return &Client{
-		client: c,
-		scheme: scheme,
+		client:    c,
+		scheme:    scheme,
+		clientset: clientset,
 	}, nil
 }
 
+// ListPodNames returns the names of pods in namespace matching labelSelector,
+// for discovering which hypershift-operator/control-plane pods to pull logs
+// from.
+func (c *Client) ListPodNames(namespace, labelSelector string) ([]string, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return names, nil
+}
+
+// GetPodLogs retrieves up to tailLines of logs for a pod's container.
+func (c *Client) GetPodLogs(namespace, podName, containerName string, tailLines int64) (string, error) {
+	opts := &corev1.PodLogOptions{
+		Container: containerName,
+		TailLines: &tailLines,
+	}
+
+	req := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, opts)
+	stream, err := req.Stream(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to open log stream: %w", err)
+	}
+	defer stream.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, stream); err != nil {
+		return "", fmt.Errorf("failed to read log stream: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
 // HostedCluster represents a simplified version of the HostedCluster CR
 type HostedCluster struct {
 	Name       string            `json:"name"`
@@ -109,11 +175,25 @@ type Condition struct {
 	Message string `json:"message,omitempty"`
 }
 
-// GetHostedClusters retrieves HostedCluster resources from the specified namespace
-func (c *Client) GetHostedClusters(namespace string) (string, error) {
-	ctx := context.Background()
+// ResourceProvider is the read surface both the live Client and the offline
+// LocalClient implement, so callers (e.g. a --offline CLI flag) can switch
+// between a live cluster and a previously-captured support bundle without
+// changing how the result is consumed.
+type ResourceProvider interface {
+	GetHostedClusters(namespace string) (string, error)
+	GetNodePools(namespace string) (string, error)
+	GetAllResources(namespace string) (string, error)
+	// GetConditionSummary returns a compact JSON readiness rollup (see
+	// pkg/llm/analyzer) for the HostedClusters and NodePools in namespace,
+	// for injecting into the LLM prompt instead of the raw condition dump.
+	GetConditionSummary(namespace string) (string, error)
+	// GetRootCauseHints returns the ranked, deterministic root-cause
+	// candidates (see pkg/llm/rootcause) for namespace, computed before the
+	// LLM ever sees the data.
+	GetRootCauseHints(namespace string) (string, error)
+}
 
-	// Create a list object for HostedClusters
+func (c *Client) listHostedClusters(namespace string) ([]unstructured.Unstructured, error) {
 	list := &unstructured.UnstructuredList{}
 	list.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   hostedClusterGVK.Group,
@@ -121,54 +201,13 @@ func (c *Client) GetHostedClusters(namespace string) (string, error) {
 		Kind:    hostedClusterGVK.Kind + "List",
 	})
 
-	// List the HostedClusters
-	if err := c.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
-		return "", fmt.Errorf("failed to list HostedClusters: %w", err)
+	if err := c.client.List(context.Background(), list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list HostedClusters: %w", err)
 	}
-
-	// Format HostedCluster information as a string
-	result := fmt.Sprintf("Found %d HostedClusters in namespace %s:\n", len(list.Items), namespace)
-
-	for _, item := range list.Items {
-		name, _, _ := unstructured.NestedString(item.Object, "metadata", "name")
-		version, _, _ := unstructured.NestedString(item.Object, "spec", "release", "image")
-		platform, _, _ := unstructured.NestedString(item.Object, "spec", "platform", "type")
-		status, _, _ := unstructured.NestedString(item.Object, "status", "phase")
-
-		result += fmt.Sprintf("- %s\n", name)
-		result += fmt.Sprintf("  • Version: %s\n", version)
-		result += fmt.Sprintf("  • Platform: %s\n", platform)
-		result += fmt.Sprintf("  • Status: %s\n", status)
-
-		// Get conditions
-		conditions, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
-		if exists && len(conditions) > 0 {
-			result += "  • Conditions:\n"
-			for _, c := range conditions {
-				condition, ok := c.(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				condType, _, _ := unstructured.NestedString(condition, "type")
-				status, _, _ := unstructured.NestedString(condition, "status")
-				reason, _, _ := unstructured.NestedString(condition, "reason")
-
-				result += fmt.Sprintf("    - %s: %s (%s)\n", condType, status, reason)
-			}
-		}
-
-		result += "\n"
-	}
-
-	return result, nil
+	return list.Items, nil
 }
 
-// GetNodePools retrieves NodePool resources from the specified namespace
-func (c *Client) GetNodePools(namespace string) (string, error) {
-	ctx := context.Background()
-
-	// Create a list object for NodePools
+func (c *Client) listNodePools(namespace string) ([]unstructured.Unstructured, error) {
 	list := &unstructured.UnstructuredList{}
 	list.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   nodePoolGVK.Group,
@@ -176,70 +215,94 @@ func (c *Client) GetNodePools(namespace string) (string, error) {
 		Kind:    nodePoolGVK.Kind + "List",
 	})
 
-	// List the NodePools
-	if err := c.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
-		return "", fmt.Errorf("failed to list NodePools: %w", err)
+	if err := c.client.List(context.Background(), list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
 	}
+	return list.Items, nil
+}
 
-	// Format NodePool information as a string
-	result := fmt.Sprintf("Found %d NodePools in namespace %s:\n", len(list.Items), namespace)
-
-	for _, item := range list.Items {
-		name, _, _ := unstructured.NestedString(item.Object, "metadata", "name")
-		clusterName, _, _ := unstructured.NestedString(item.Object, "spec", "clusterName")
-		instanceType, _, _ := unstructured.NestedString(item.Object, "spec", "platform", "aws", "instanceType")
-		replicas, _, _ := unstructured.NestedInt64(item.Object, "spec", "replicas")
-
-		result += fmt.Sprintf("- %s\n", name)
-		result += fmt.Sprintf("  • Cluster: %s\n", clusterName)
-		result += fmt.Sprintf("  • Instance Type: %s\n", instanceType)
-		result += fmt.Sprintf("  • Replicas: %d\n", replicas)
+// GetHostedClusters retrieves HostedCluster resources from the specified namespace
+func (c *Client) GetHostedClusters(namespace string) (string, error) {
+	items, err := c.listHostedClusters(namespace)
+	if err != nil {
+		return "", err
+	}
+	return renderHostedClusters(items, namespace), nil
+}
 
-		// Check for autoscaling
-		minReplicas, minExists, _ := unstructured.NestedInt64(item.Object, "spec", "autoScaling", "min")
-		maxReplicas, maxExists, _ := unstructured.NestedInt64(item.Object, "spec", "autoScaling", "max")
+// GetNodePools retrieves NodePool resources from the specified namespace
+func (c *Client) GetNodePools(namespace string) (string, error) {
+	items, err := c.listNodePools(namespace)
+	if err != nil {
+		return "", err
+	}
+	return renderNodePools(items, namespace), nil
+}
 
-		if minExists && maxExists {
-			result += fmt.Sprintf("  • Auto Scaling: Enabled (min: %d, max: %d)\n", minReplicas, maxReplicas)
-		} else {
-			result += "  • Auto Scaling: Disabled\n"
-		}
+// GetAllResources retrieves both HostedClusters and NodePools from the specified namespace
+func (c *Client) GetAllResources(namespace string) (string, error) {
+	hostedClusters, err := c.GetHostedClusters(namespace)
+	if err != nil {
+		return "", err
+	}
 
-		// Get conditions
-		conditions, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
-		if exists && len(conditions) > 0 {
-			result += "  • Conditions:\n"
-			for _, c := range conditions {
-				condition, ok := c.(map[string]interface{})
-				if !ok {
-					continue
-				}
+	nodePools, err := c.GetNodePools(namespace)
+	if err != nil {
+		return "", err
+	}
 
-				condType, _, _ := unstructured.NestedString(condition, "type")
-				status, _, _ := unstructured.NestedString(condition, "status")
-				reason, _, _ := unstructured.NestedString(condition, "reason")
+	return hostedClusters + "\n" + nodePools, nil
+}
 
-				result += fmt.Sprintf("    - %s: %s (%s)\n", condType, status, reason)
-			}
-		}
+// GetConditionSummary retrieves HostedClusters and NodePools from namespace
+// and classifies their conditions into the compact JSON readiness rollup.
+func (c *Client) GetConditionSummary(namespace string) (string, error) {
+	hcItems, err := c.listHostedClusters(namespace)
+	if err != nil {
+		return "", err
+	}
 
-		result += "\n"
+	npItems, err := c.listNodePools(namespace)
+	if err != nil {
+		return "", err
 	}
 
-	return result, nil
+	return summarizeConditions(hcItems, npItems)
 }
 
-// GetAllResources retrieves both HostedClusters and NodePools from the specified namespace
-func (c *Client) GetAllResources(namespace string) (string, error) {
-	hostedClusters, err := c.GetHostedClusters(namespace)
+// GetRootCauseHints retrieves HostedClusters and NodePools from namespace
+// and runs the deterministic root-cause rules over their conditions.
+func (c *Client) GetRootCauseHints(namespace string) (string, error) {
+	hcItems, err := c.listHostedClusters(namespace)
 	if err != nil {
 		return "", err
 	}
 
-	nodePools, err := c.GetNodePools(namespace)
+	npItems, err := c.listNodePools(namespace)
 	if err != nil {
 		return "", err
 	}
 
-	return hostedClusters + "\n" + nodePools, nil
+	return rootCauseHints(hcItems, npItems), nil
+}
+
+// GetResourceConditions retrieves HostedClusters and NodePools from
+// namespace and returns their conditions in the structured form
+// fleet.Aggregate consumes, the same extraction GetConditionSummary uses
+// before rendering its JSON rollup.
+func (c *Client) GetResourceConditions(namespace string) ([]analyzer.ResourceConditions, error) {
+	hcItems, err := c.listHostedClusters(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	npItems, err := c.listNodePools(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []analyzer.ResourceConditions
+	resources = append(resources, extractConditions("HostedCluster", hcItems)...)
+	resources = append(resources, extractConditions("NodePool", npItems)...)
+	return resources, nil
 }
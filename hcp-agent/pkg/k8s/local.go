@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// LocalClient implements the same read surface as Client (GetHostedClusters,
+// GetNodePools, GetAllResources), but reads resources from a directory of
+// previously-captured YAML/JSON manifests -- an RCA bundle or support-bundle
+// dump -- instead of a live API server. This lets --offline analysis run
+// against customer-provided data without cluster access.
+type LocalClient struct {
+	dir string
+}
+
+// NewLocalClient creates a LocalClient that reads resource manifests from
+// dir and its subdirectories.
+func NewLocalClient(dir string) *LocalClient {
+	return &LocalClient{dir: dir}
+}
+
+// GetHostedClusters retrieves HostedCluster resources captured under dir.
+func (c *LocalClient) GetHostedClusters(namespace string) (string, error) {
+	items, err := c.loadItems(hostedClusterGVK.Kind, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to load HostedClusters from %s: %w", c.dir, err)
+	}
+	return renderHostedClusters(items, namespace), nil
+}
+
+// GetNodePools retrieves NodePool resources captured under dir.
+func (c *LocalClient) GetNodePools(namespace string) (string, error) {
+	items, err := c.loadItems(nodePoolGVK.Kind, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to load NodePools from %s: %w", c.dir, err)
+	}
+	return renderNodePools(items, namespace), nil
+}
+
+// GetAllResources retrieves both HostedClusters and NodePools captured
+// under dir.
+func (c *LocalClient) GetAllResources(namespace string) (string, error) {
+	hostedClusters, err := c.GetHostedClusters(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	nodePools, err := c.GetNodePools(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return hostedClusters + "\n" + nodePools, nil
+}
+
+// GetConditionSummary classifies the conditions of the HostedClusters and
+// NodePools captured under dir into the compact JSON readiness rollup.
+func (c *LocalClient) GetConditionSummary(namespace string) (string, error) {
+	hcItems, err := c.loadItems(hostedClusterGVK.Kind, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to load HostedClusters from %s: %w", c.dir, err)
+	}
+
+	npItems, err := c.loadItems(nodePoolGVK.Kind, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to load NodePools from %s: %w", c.dir, err)
+	}
+
+	return summarizeConditions(hcItems, npItems)
+}
+
+// GetRootCauseHints runs the deterministic root-cause rules over the
+// conditions of the HostedClusters and NodePools captured under dir.
+func (c *LocalClient) GetRootCauseHints(namespace string) (string, error) {
+	hcItems, err := c.loadItems(hostedClusterGVK.Kind, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to load HostedClusters from %s: %w", c.dir, err)
+	}
+
+	npItems, err := c.loadItems(nodePoolGVK.Kind, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to load NodePools from %s: %w", c.dir, err)
+	}
+
+	return rootCauseHints(hcItems, npItems), nil
+}
+
+func (c *LocalClient) loadItems(kind, namespace string) ([]unstructured.Unstructured, error) {
+	all, err := LoadDirectory(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []unstructured.Unstructured
+	for _, item := range all {
+		if item.GetKind() != kind {
+			continue
+		}
+		if namespace != "" && item.GetNamespace() != namespace {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// LoadDirectory walks dir and decodes every .yaml, .yml and .json file into
+// unstructured objects, tolerating multi-document YAML files. It's shared by
+// LocalClient's per-kind filtering and by callers (e.g. an offline CLI path)
+// that need every captured object regardless of kind.
+func LoadDirectory(dir string) ([]unstructured.Unstructured, error) {
+	var items []unstructured.Unstructured
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer f.Close()
+
+		decoder := yamlutil.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			u := unstructured.Unstructured{}
+			if err := decoder.Decode(&u.Object); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return fmt.Errorf("failed to decode %s: %w", path, err)
+			}
+			if len(u.Object) == 0 {
+				continue
+			}
+			items = append(items, u)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,157 @@
+package k8s
+
+import (
+	"fmt"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/rootcause"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// renderHostedClusters formats a list of HostedCluster objects the same way
+// regardless of whether they came from a live List call or a LocalClient
+// reading captured manifests from disk.
+func renderHostedClusters(items []unstructured.Unstructured, namespace string) string {
+	result := fmt.Sprintf("Found %d HostedClusters in namespace %s:\n", len(items), namespace)
+
+	for _, item := range items {
+		name, _, _ := unstructured.NestedString(item.Object, "metadata", "name")
+		version, _, _ := unstructured.NestedString(item.Object, "spec", "release", "image")
+		platform, _, _ := unstructured.NestedString(item.Object, "spec", "platform", "type")
+		status, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+
+		result += fmt.Sprintf("- %s\n", name)
+		result += fmt.Sprintf("  • Version: %s\n", version)
+		result += fmt.Sprintf("  • Platform: %s\n", platform)
+		result += fmt.Sprintf("  • Status: %s\n", status)
+
+		conditions, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+		if exists && len(conditions) > 0 {
+			result += "  • Conditions:\n"
+			for _, c := range conditions {
+				condition, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				condType, _, _ := unstructured.NestedString(condition, "type")
+				status, _, _ := unstructured.NestedString(condition, "status")
+				reason, _, _ := unstructured.NestedString(condition, "reason")
+
+				result += fmt.Sprintf("    - %s: %s (%s)\n", condType, status, reason)
+			}
+		}
+
+		result += "\n"
+	}
+
+	return result
+}
+
+// extractConditions reads kind-tagged ResourceConditions out of items for
+// the analyzer package to classify, the structured counterpart to
+// renderHostedClusters/renderNodePools' flattened text. Unlike those
+// functions it keeps each condition's message, since a severity
+// classification without the message to back it up isn't worth much to the
+// LLM.
+func extractConditions(kind string, items []unstructured.Unstructured) []analyzer.ResourceConditions {
+	out := make([]analyzer.ResourceConditions, 0, len(items))
+	for _, item := range items {
+		name, _, _ := unstructured.NestedString(item.Object, "metadata", "name")
+
+		raw, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+		if !exists {
+			continue
+		}
+
+		var conditions []analyzer.Condition
+		for _, c := range raw {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			condType, _, _ := unstructured.NestedString(condition, "type")
+			status, _, _ := unstructured.NestedString(condition, "status")
+			reason, _, _ := unstructured.NestedString(condition, "reason")
+			message, _, _ := unstructured.NestedString(condition, "message")
+			conditions = append(conditions, analyzer.Condition{
+				Type: condType, Status: status, Reason: reason, Message: message,
+			})
+		}
+
+		out = append(out, analyzer.ResourceConditions{Kind: kind, Name: name, Conditions: conditions})
+	}
+	return out
+}
+
+// summarizeConditions classifies every condition off hcItems/npItems and
+// renders the resulting per-resource readiness rollup as the compact JSON
+// blob injected into the LLM prompt in place of the raw condition dump.
+func summarizeConditions(hcItems, npItems []unstructured.Unstructured) (string, error) {
+	var resources []analyzer.ResourceConditions
+	resources = append(resources, extractConditions("HostedCluster", hcItems)...)
+	resources = append(resources, extractConditions("NodePool", npItems)...)
+
+	summaries := analyzer.NewConditionSummarizer().Summarize(resources)
+	return analyzer.RenderJSON(summaries)
+}
+
+// rootCauseHints runs the deterministic, pre-LLM root-cause rules (see
+// pkg/llm/rootcause) over hcItems/npItems and renders the ranked result as
+// the prompt section fed to the LLM alongside the condition summary.
+func rootCauseHints(hcItems, npItems []unstructured.Unstructured) string {
+	var resources []analyzer.ResourceConditions
+	resources = append(resources, extractConditions("HostedCluster", hcItems)...)
+	resources = append(resources, extractConditions("NodePool", npItems)...)
+
+	return rootcause.RenderPromptSection(rootcause.Generate(resources))
+}
+
+// renderNodePools formats a list of NodePool objects the same way regardless
+// of whether they came from a live List call or a LocalClient reading
+// captured manifests from disk.
+func renderNodePools(items []unstructured.Unstructured, namespace string) string {
+	result := fmt.Sprintf("Found %d NodePools in namespace %s:\n", len(items), namespace)
+
+	for _, item := range items {
+		name, _, _ := unstructured.NestedString(item.Object, "metadata", "name")
+		clusterName, _, _ := unstructured.NestedString(item.Object, "spec", "clusterName")
+		instanceType, _, _ := unstructured.NestedString(item.Object, "spec", "platform", "aws", "instanceType")
+		replicas, _, _ := unstructured.NestedInt64(item.Object, "spec", "replicas")
+
+		result += fmt.Sprintf("- %s\n", name)
+		result += fmt.Sprintf("  • Cluster: %s\n", clusterName)
+		result += fmt.Sprintf("  • Instance Type: %s\n", instanceType)
+		result += fmt.Sprintf("  • Replicas: %d\n", replicas)
+
+		minReplicas, minExists, _ := unstructured.NestedInt64(item.Object, "spec", "autoScaling", "min")
+		maxReplicas, maxExists, _ := unstructured.NestedInt64(item.Object, "spec", "autoScaling", "max")
+
+		if minExists && maxExists {
+			result += fmt.Sprintf("  • Auto Scaling: Enabled (min: %d, max: %d)\n", minReplicas, maxReplicas)
+		} else {
+			result += "  • Auto Scaling: Disabled\n"
+		}
+
+		conditions, exists, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+		if exists && len(conditions) > 0 {
+			result += "  • Conditions:\n"
+			for _, c := range conditions {
+				condition, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				condType, _, _ := unstructured.NestedString(condition, "type")
+				status, _, _ := unstructured.NestedString(condition, "status")
+				reason, _, _ := unstructured.NestedString(condition, "reason")
+
+				result += fmt.Sprintf("    - %s: %s (%s)\n", condType, status, reason)
+			}
+		}
+
+		result += "\n"
+	}
+
+	return result
+}
@@ -0,0 +1,773 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Analyzer is implemented by every LLM backend adapter so the Reconciler
+// can request an analysis without depending on a concrete provider.
+type Analyzer interface {
+	Analyze(ctx context.Context, k8sData, question string) (string, error)
+	// AnalyzeStream behaves like Analyze, but streams the answer over the
+	// returned channel as it's generated instead of waiting for the full
+	// response. Backends with no native streaming API here (anthropic,
+	// bedrock, llamacpp, local) fall back to running Analyze and emitting its result as a
+	// single Chunk; see analyzeStreamFallback.
+	AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error)
+	// AnalyzeFleet behaves like Analyze, but against fleetJSON -- an
+	// already-aggregated fleet.Rollup (see pkg/fleet) spanning many
+	// HostedClusters/NodePools, possibly across multiple management
+	// clusters -- using the "fleet summary" prompt mode instead of the
+	// single-cluster deep dive.
+	AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error)
+}
+
+// Chunk is one piece of a streamed Analyze response. The producer always
+// closes the channel; a final Chunk with Err set (and Text empty) is sent
+// if the stream ends in error.
+type Chunk struct {
+	Text string
+	Err  error
+}
+
+// analyzeStreamFallback runs analyze and emits its entire result as a
+// single Chunk over the returned channel, for adapters whose backend has no
+// incremental streaming API of its own.
+func analyzeStreamFallback(ctx context.Context, analyze func(context.Context, string, string) (string, error), k8sData, question string) (<-chan Chunk, error) {
+	ch := make(chan Chunk, 1)
+	go func() {
+		defer close(ch)
+		text, err := analyze(ctx, k8sData, question)
+		if err != nil {
+			ch <- Chunk{Err: err}
+			return
+		}
+		ch <- Chunk{Text: text}
+	}()
+	return ch, nil
+}
+
+// AdapterName identifies a supported LLM backend.
+type AdapterName string
+
+const (
+	AdapterOllama    AdapterName = "ollama"
+	AdapterOpenAI    AdapterName = "openai"
+	AdapterLlamaCpp  AdapterName = "llamacpp"
+	AdapterBedrock   AdapterName = "bedrock"
+	AdapterVLLM      AdapterName = "vllm"
+	AdapterAnthropic AdapterName = "anthropic"
+	AdapterLocal     AdapterName = "local"
+)
+
+// Config selects and configures an LLM backend adapter.
+type Config struct {
+	Adapter AdapterName
+	BaseURL string
+	Model   string
+	APIKey  string
+	Region  string // not read by any adapter; Bedrock's endpoint comes from BaseURL
+
+	// MaxRetries and Timeout are shared by every HTTP-based adapter.
+	MaxRetries int
+	Timeout    time.Duration
+	// MaxTokens caps the size of the prompt sent to the backend, used for
+	// simple token budgeting until a real tokenizer is wired in.
+	MaxTokens int
+
+	// LocalBinaryPath and LocalModelPath configure the local adapter, which
+	// shells out to a llama.cpp CLI binary against an on-disk GGUF model
+	// instead of calling an HTTP backend. Used only when Adapter == AdapterLocal.
+	LocalBinaryPath string
+	LocalModelPath  string
+
+	// Debug, when set, logs every HTTP-based adapter's request and response
+	// bodies at info level, for diagnosing backend issues.
+	Debug bool
+
+	// CostPer1KTokens, if set, is the backend's price in USD per 1,000
+	// tokens, used by pkg/llm/cache to accumulate estimated spend. Zero
+	// disables cost accounting, the right default for free/local backends.
+	CostPer1KTokens float64
+}
+
+// adapterConstructors maps an adapter name to the function that builds it,
+// mirroring the registry pattern used by structured-logging libraries so new
+// backends can be added without touching the factory itself.
+var adapterConstructors = map[AdapterName]func(Config) (Analyzer, error){
+	AdapterOllama:    newOllamaAdapter,
+	AdapterOpenAI:    newOpenAIAdapter,
+	AdapterLlamaCpp:  newLlamaCppAdapter,
+	AdapterBedrock:   newBedrockAdapter,
+	AdapterVLLM:      newVLLMAdapter,
+	AdapterAnthropic: newAnthropicAdapter,
+	AdapterLocal:     newLocalAdapter,
+}
+
+// NewAnalyzer builds the Analyzer selected by cfg.Adapter. If cfg.Adapter is
+// empty, it falls back to the LLM_ADAPTER environment variable, so
+// deployments can select a backend without threading a flag through.
+func NewAnalyzer(cfg Config) (Analyzer, error) {
+	if cfg.Adapter == "" {
+		if envAdapter := os.Getenv("LLM_ADAPTER"); envAdapter != "" {
+			cfg.Adapter = AdapterName(envAdapter)
+		}
+	}
+
+	ctor, ok := adapterConstructors[cfg.Adapter]
+	if !ok {
+		return nil, fmt.Errorf("unknown llm adapter %q", cfg.Adapter)
+	}
+	return ctor(cfg)
+}
+
+// httpAdapter holds the retry/backoff/timeout plumbing shared by every
+// HTTP-based adapter so each concrete adapter only has to build a
+// provider-specific request body and parse its response.
+type httpAdapter struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func newHTTPAdapter(cfg Config) httpAdapter {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+	return httpAdapter{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// do posts body to url and returns the response bytes, retrying on 5xx
+// responses and transient network errors with exponential backoff.
+func (a httpAdapter) do(ctx context.Context, url string, headers map[string]string, body []byte) ([]byte, error) {
+	maxRetries := a.cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	if a.cfg.Debug {
+		log.Info("llm request", "url", url, "body", string(body))
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff(attempt))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := a.httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("request failed: %w", err)
+			continue
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read response: %w", err)
+			continue
+		}
+
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("backend error (status %d): %s", resp.StatusCode, string(respBody))
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("backend error (status %d): %s", resp.StatusCode, string(respBody))
+		}
+
+		if a.cfg.Debug {
+			log.Info("llm response", "url", url, "body", string(respBody))
+		}
+		return respBody, nil
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// backoff returns the delay before retry attempt (1-based), doubling with
+// each attempt from 250ms: 250ms, 500ms, 1s, ...
+func backoff(attempt int) time.Duration {
+	return time.Duration(1<<uint(attempt-1)) * 250 * time.Millisecond
+}
+
+// budgetPrompt truncates k8sData so the rendered prompt stays within a rough
+// character budget until a real tokenizer-based estimator is available.
+func (a httpAdapter) budgetPrompt(k8sData string) string {
+	if a.cfg.MaxTokens <= 0 {
+		return k8sData
+	}
+	// Rough estimate: ~4 characters per token.
+	maxChars := a.cfg.MaxTokens * 4
+	if len(k8sData) <= maxChars {
+		return k8sData
+	}
+	return k8sData[:maxChars] + "\n... (truncated to fit token budget)"
+}
+
+// ollamaAdapter adapts the existing OllamaClient to the Analyzer interface.
+type ollamaAdapter struct {
+	client *OllamaClient
+}
+
+func newOllamaAdapter(cfg Config) (Analyzer, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("ollama adapter requires BaseURL")
+	}
+	return &ollamaAdapter{client: NewOllamaClient(cfg.BaseURL, cfg.Model)}, nil
+}
+
+func (a *ollamaAdapter) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	return a.client.Analyze(ctx, k8sData, question)
+}
+
+func (a *ollamaAdapter) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	return a.client.AnalyzeStream(ctx, k8sData, question)
+}
+
+func (a *ollamaAdapter) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	return a.client.AnalyzeFleet(ctx, fleetJSON, question)
+}
+
+// openAIChatRequest is the minimal subset of the OpenAI-compatible
+// /v1/chat/completions request body this adapter needs.
+type openAIChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []openAIChatMessage `json:"messages"`
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIChatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// openAIAdapter talks to any OpenAI-compatible chat completions endpoint,
+// which also covers most self-hosted gateways that mimic the OpenAI API.
+type openAIAdapter struct {
+	httpAdapter
+}
+
+func newOpenAIAdapter(cfg Config) (Analyzer, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("openai adapter requires BaseURL")
+	}
+	return &openAIAdapter{httpAdapter: newHTTPAdapter(cfg)}, nil
+}
+
+func (a *openAIAdapter) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	reqBody, err := json.Marshal(openAIChatRequest{
+		Model: a.cfg.Model,
+		Messages: []openAIChatMessage{
+			{Role: "system", Content: "You are a Kubernetes expert specializing in OpenShift HyperShift."},
+			{Role: "user", Content: buildPrompt(a.budgetPrompt(k8sData), question)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{}
+	if a.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + a.cfg.APIKey
+	}
+
+	respBody, err := a.do(ctx, a.cfg.BaseURL+"/v1/chat/completions", headers, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed openAIChatResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("openai response had no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
+
+// AnalyzeStream streams an OpenAI-compatible /v1/chat/completions response
+// over Server-Sent Events, emitting each delta's content as a Chunk.
+func (a *openAIAdapter) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model":  a.cfg.Model,
+		"stream": true,
+		"messages": []openAIChatMessage{
+			{Role: "system", Content: "You are a Kubernetes expert specializing in OpenShift HyperShift."},
+			{Role: "user", Content: buildPrompt(a.budgetPrompt(k8sData), question)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.BaseURL+"/v1/chat/completions", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.cfg.APIKey)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("backend error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return
+			}
+
+			var event struct {
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+			}
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				ch <- Chunk{Err: fmt.Errorf("failed to decode stream event: %w", err)}
+				return
+			}
+			if len(event.Choices) > 0 && event.Choices[0].Delta.Content != "" {
+				ch <- Chunk{Text: event.Choices[0].Delta.Content}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			ch <- Chunk{Err: fmt.Errorf("failed to read stream: %w", err)}
+		}
+	}()
+
+	return ch, nil
+}
+
+// AnalyzeFleet behaves like Analyze, but renders fleetJSON through
+// buildFleetPrompt instead of buildPrompt.
+func (a *openAIAdapter) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	reqBody, err := json.Marshal(openAIChatRequest{
+		Model: a.cfg.Model,
+		Messages: []openAIChatMessage{
+			{Role: "system", Content: "You are a Kubernetes expert specializing in OpenShift HyperShift."},
+			{Role: "user", Content: buildFleetPrompt(fleetJSON, question)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{}
+	if a.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + a.cfg.APIKey
+	}
+
+	respBody, err := a.do(ctx, a.cfg.BaseURL+"/v1/chat/completions", headers, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed openAIChatResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("openai response had no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
+
+// vllmAdapter talks to a vLLM OpenAI-compatible server. vLLM serves the same
+// /v1/chat/completions contract, so it reuses the OpenAI wire format.
+type vllmAdapter struct {
+	openAIAdapter
+}
+
+func newVLLMAdapter(cfg Config) (Analyzer, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("vllm adapter requires BaseURL")
+	}
+	return &vllmAdapter{openAIAdapter: openAIAdapter{httpAdapter: newHTTPAdapter(cfg)}}, nil
+}
+
+// llamaCppAdapter talks to a local llama.cpp server's /completion endpoint.
+type llamaCppAdapter struct {
+	httpAdapter
+}
+
+func newLlamaCppAdapter(cfg Config) (Analyzer, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("llamacpp adapter requires BaseURL")
+	}
+	return &llamaCppAdapter{httpAdapter: newHTTPAdapter(cfg)}, nil
+}
+
+func (a *llamaCppAdapter) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	prompt := buildPrompt(a.budgetPrompt(k8sData), question)
+	reqBody, err := json.Marshal(map[string]any{
+		"prompt": prompt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respBody, err := a.do(ctx, a.cfg.BaseURL+"/completion", nil, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return parsed.Content, nil
+}
+
+// AnalyzeStream falls back to a single Analyze call: llama.cpp's
+// /completion endpoint supports streaming, but not worth a second wire
+// format here until a caller actually needs token-by-token output from it.
+func (a *llamaCppAdapter) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	return analyzeStreamFallback(ctx, a.Analyze, k8sData, question)
+}
+
+// AnalyzeFleet behaves like Analyze, but renders fleetJSON through
+// buildFleetPrompt instead of buildPrompt.
+func (a *llamaCppAdapter) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	prompt := buildFleetPrompt(fleetJSON, question)
+	reqBody, err := json.Marshal(map[string]any{
+		"prompt": prompt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respBody, err := a.do(ctx, a.cfg.BaseURL+"/completion", nil, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return parsed.Content, nil
+}
+
+// bedrockAdapter invokes an Amazon Bedrock model through the runtime's HTTP
+// InvokeModel endpoint. It expects cfg.BaseURL to already point at the
+// region-specific bedrock-runtime host and cfg.APIKey to carry a pre-signed
+// auth token, keeping this adapter free of the AWS SDK.
+type bedrockAdapter struct {
+	httpAdapter
+}
+
+func newBedrockAdapter(cfg Config) (Analyzer, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("bedrock adapter requires BaseURL")
+	}
+	return &bedrockAdapter{httpAdapter: newHTTPAdapter(cfg)}, nil
+}
+
+func (a *bedrockAdapter) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	prompt := buildPrompt(a.budgetPrompt(k8sData), question)
+	reqBody, err := json.Marshal(map[string]any{
+		"prompt":     prompt,
+		"max_tokens": a.cfg.MaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{}
+	if a.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + a.cfg.APIKey
+	}
+
+	url := fmt.Sprintf("%s/model/%s/invoke", a.cfg.BaseURL, a.cfg.Model)
+	respBody, err := a.do(ctx, url, headers, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Completion string `json:"completion"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return parsed.Completion, nil
+}
+
+// AnalyzeStream falls back to a single Analyze call; InvokeModelWithResponseStream
+// needs AWS's event-stream framing rather than plain HTTP, which isn't worth
+// adding until a caller needs incremental Bedrock output.
+func (a *bedrockAdapter) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	return analyzeStreamFallback(ctx, a.Analyze, k8sData, question)
+}
+
+// AnalyzeFleet behaves like Analyze, but renders fleetJSON through
+// buildFleetPrompt instead of buildPrompt.
+func (a *bedrockAdapter) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	prompt := buildFleetPrompt(fleetJSON, question)
+	reqBody, err := json.Marshal(map[string]any{
+		"prompt":     prompt,
+		"max_tokens": a.cfg.MaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{}
+	if a.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + a.cfg.APIKey
+	}
+
+	url := fmt.Sprintf("%s/model/%s/invoke", a.cfg.BaseURL, a.cfg.Model)
+	respBody, err := a.do(ctx, url, headers, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Completion string `json:"completion"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return parsed.Completion, nil
+}
+
+// anthropicAdapter talks to Anthropic's /v1/messages API.
+type anthropicAdapter struct {
+	httpAdapter
+}
+
+func newAnthropicAdapter(cfg Config) (Analyzer, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("anthropic adapter requires APIKey")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.anthropic.com"
+	}
+	return &anthropicAdapter{httpAdapter: newHTTPAdapter(cfg)}, nil
+}
+
+func (a *anthropicAdapter) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model":      a.cfg.Model,
+		"max_tokens": maxOr(a.cfg.MaxTokens, 2000),
+		"system":     "You are a Kubernetes expert specializing in OpenShift HyperShift.",
+		"messages": []openAIChatMessage{
+			{Role: "user", Content: buildPrompt(a.budgetPrompt(k8sData), question)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{
+		"x-api-key":         a.cfg.APIKey,
+		"anthropic-version": "2023-06-01",
+	}
+
+	respBody, err := a.do(ctx, a.cfg.BaseURL+"/v1/messages", headers, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Content) == 0 {
+		return "", fmt.Errorf("anthropic response had no content")
+	}
+	return parsed.Content[0].Text, nil
+}
+
+// AnalyzeStream falls back to a single Analyze call; Anthropic's streaming
+// API uses a different SSE event schema than OpenAI's, not worth a second
+// implementation until a caller needs incremental Anthropic output.
+func (a *anthropicAdapter) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	return analyzeStreamFallback(ctx, a.Analyze, k8sData, question)
+}
+
+// AnalyzeFleet behaves like Analyze, but renders fleetJSON through
+// buildFleetPrompt instead of buildPrompt.
+func (a *anthropicAdapter) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model":      a.cfg.Model,
+		"max_tokens": maxOr(a.cfg.MaxTokens, 2000),
+		"system":     "You are a Kubernetes expert specializing in OpenShift HyperShift.",
+		"messages": []openAIChatMessage{
+			{Role: "user", Content: buildFleetPrompt(fleetJSON, question)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{
+		"x-api-key":         a.cfg.APIKey,
+		"anthropic-version": "2023-06-01",
+	}
+
+	respBody, err := a.do(ctx, a.cfg.BaseURL+"/v1/messages", headers, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(parsed.Content) == 0 {
+		return "", fmt.Errorf("anthropic response had no content")
+	}
+	return parsed.Content[0].Text, nil
+}
+
+func maxOr(v, fallback int) int {
+	if v <= 0 {
+		return fallback
+	}
+	return v
+}
+
+// localAdapter runs a llama.cpp CLI binary as a subprocess against an
+// on-disk GGUF model, for air-gapped or no-HTTP-backend deployments.
+type localAdapter struct {
+	binaryPath string
+	modelPath  string
+	cfg        Config
+}
+
+func newLocalAdapter(cfg Config) (Analyzer, error) {
+	if cfg.LocalBinaryPath == "" {
+		return nil, fmt.Errorf("local adapter requires LocalBinaryPath")
+	}
+	if cfg.LocalModelPath == "" {
+		return nil, fmt.Errorf("local adapter requires LocalModelPath")
+	}
+	return &localAdapter{binaryPath: cfg.LocalBinaryPath, modelPath: cfg.LocalModelPath, cfg: cfg}, nil
+}
+
+func (a *localAdapter) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	prompt := buildPrompt(a.budgetPromptChars(k8sData), question)
+
+	args := []string{"-m", a.modelPath, "-p", prompt, "--no-display-prompt"}
+	if a.cfg.MaxTokens > 0 {
+		args = append(args, "-n", fmt.Sprintf("%d", a.cfg.MaxTokens))
+	}
+
+	cmd := exec.CommandContext(ctx, a.binaryPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("llama.cpp invocation failed: %w (stderr: %s)", err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// AnalyzeStream falls back to a single Analyze call: the llama.cpp CLI
+// binary only emits its full completion on exit, so there is nothing to
+// stream incrementally.
+func (a *localAdapter) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	return analyzeStreamFallback(ctx, a.Analyze, k8sData, question)
+}
+
+// AnalyzeFleet behaves like Analyze, but renders fleetJSON through
+// buildFleetPrompt instead of buildPrompt.
+func (a *localAdapter) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	prompt := buildFleetPrompt(fleetJSON, question)
+
+	args := []string{"-m", a.modelPath, "-p", prompt, "--no-display-prompt"}
+	if a.cfg.MaxTokens > 0 {
+		args = append(args, "-n", fmt.Sprintf("%d", a.cfg.MaxTokens))
+	}
+
+	cmd := exec.CommandContext(ctx, a.binaryPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("llama.cpp invocation failed: %w (stderr: %s)", err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// budgetPromptChars is the local adapter's equivalent of httpAdapter's
+// budgetPrompt -- it has no httpAdapter to embed, so it duplicates the
+// rough char-per-token truncation rather than pulling in the HTTP plumbing
+// it doesn't otherwise need.
+func (a *localAdapter) budgetPromptChars(k8sData string) string {
+	if a.cfg.MaxTokens <= 0 {
+		return k8sData
+	}
+	maxChars := a.cfg.MaxTokens * 4
+	if len(k8sData) <= maxChars {
+		return k8sData
+	}
+	return k8sData[:maxChars] + "\n... (truncated to fit token budget)"
+}
@@ -0,0 +1,217 @@
+// Package analyzer turns the raw Type/Status/Reason/Message conditions off a
+// HostedCluster or NodePool into a compact, structured summary an LLM can
+// reason about directly, instead of re-deriving severity from a wall of
+// rendered YAML-ish text on every prompt.
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Bucket is the severity a condition (or a resource's rollup of conditions)
+// is classified into.
+type Bucket string
+
+const (
+	BucketHealthy            Bucket = "Healthy"
+	BucketProgressing        Bucket = "Progressing"
+	BucketDegraded           Bucket = "Degraded"
+	BucketUserActionRequired Bucket = "UserActionRequired"
+)
+
+// bucketSeverity orders buckets from least to most urgent, so a resource's
+// rollup can be computed as "the worst bucket seen" via a simple max.
+var bucketSeverity = map[Bucket]int{
+	BucketHealthy:            0,
+	BucketProgressing:        1,
+	BucketDegraded:           2,
+	BucketUserActionRequired: 3,
+}
+
+// Condition is the Type/Status/Reason/Message tuple read off a resource's
+// status.conditions, independent of whether it came from a live unstructured
+// object or a captured YAML/JSON manifest.
+type Condition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// ResourceConditions groups a resource's conditions under its kind and name,
+// e.g. Kind: "HostedCluster", Name: "my-cluster".
+type ResourceConditions struct {
+	Kind       string
+	Name       string
+	Conditions []Condition
+}
+
+// FailingCondition is a condition that classified as anything other than
+// BucketHealthy, surfaced in a ResourceSummary for the LLM to focus on.
+type FailingCondition struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Bucket  Bucket `json:"bucket"`
+}
+
+// ResourceSummary is the compact, LLM-facing rollup for a single resource.
+type ResourceSummary struct {
+	Cluster            string             `json:"cluster"`
+	Readiness          Bucket             `json:"readiness"`
+	FailingConditions  []FailingCondition `json:"failing_conditions"`
+	SuspectedRootCause string             `json:"suspected_root_cause,omitempty"`
+}
+
+// classifyRule maps a condition to a Bucket when Type, Status and Reason all
+// match (an empty field acts as a wildcard). Rules are tried in order, first
+// match wins, so put the more specific rules first.
+type classifyRule struct {
+	Type   string
+	Status string
+	Reason string
+	Bucket Bucket
+}
+
+func (r classifyRule) matches(c Condition) bool {
+	if r.Type != "" && r.Type != c.Type {
+		return false
+	}
+	if r.Status != "" && r.Status != c.Status {
+		return false
+	}
+	if r.Reason != "" && r.Reason != c.Reason {
+		return false
+	}
+	return true
+}
+
+// defaultRules encodes the same Type/Status semantics as the HostedCluster
+// and NodePool condition dictionaries baked into buildPrompt: Progressing
+// and Degraded invert the usual "True is good" reading, a handful of
+// validation-style conditions only ever go False because of something the
+// user needs to fix, and an Unknown status means the controller hasn't
+// settled on an answer yet rather than a hard failure.
+var defaultRules = []classifyRule{
+	{Type: "ValidReleaseImage", Status: "False", Bucket: BucketUserActionRequired},
+	{Type: "ValidConfiguration", Status: "False", Bucket: BucketUserActionRequired},
+	{Type: "ValidHostedControlPlaneConfiguration", Status: "False", Bucket: BucketUserActionRequired},
+	{Type: "ValidOIDCConfiguration", Status: "False", Bucket: BucketUserActionRequired},
+	{Type: "ValidIDPConfiguration", Status: "False", Bucket: BucketUserActionRequired},
+	{Type: "SupportedHostedCluster", Status: "False", Bucket: BucketUserActionRequired},
+
+	{Type: "Progressing", Status: "True", Bucket: BucketProgressing},
+	{Type: "Progressing", Status: "False", Bucket: BucketHealthy},
+
+	{Type: "Degraded", Status: "True", Bucket: BucketDegraded},
+	{Type: "Degraded", Status: "False", Bucket: BucketHealthy},
+
+	{Status: "Unknown", Bucket: BucketProgressing},
+}
+
+// defaultGatingTypes are the condition types a resource's overall Readiness
+// is rolled up from -- mirroring how the Cluster API "improve status"
+// proposal derives a top-level Ready/Available from a curated set of
+// sub-conditions rather than every condition a resource happens to carry.
+var defaultGatingTypes = []string{
+	"Available",
+	"Progressing",
+	"Degraded",
+	"ValidReleaseImage",
+	"ValidConfiguration",
+}
+
+// ConditionSummarizer classifies raw conditions into severity buckets and
+// rolls them up per resource.
+type ConditionSummarizer struct {
+	rules       []classifyRule
+	gatingTypes map[string]bool
+}
+
+// NewConditionSummarizer builds a ConditionSummarizer using the default
+// classification rules and gating condition types.
+func NewConditionSummarizer() *ConditionSummarizer {
+	gating := make(map[string]bool, len(defaultGatingTypes))
+	for _, t := range defaultGatingTypes {
+		gating[t] = true
+	}
+	return &ConditionSummarizer{rules: defaultRules, gatingTypes: gating}
+}
+
+// Classify returns the Bucket c falls into. A condition that matches none of
+// the rules falls back to Healthy when True and Degraded otherwise, since
+// most HyperShift conditions follow that convention.
+func (s *ConditionSummarizer) Classify(c Condition) Bucket {
+	for _, rule := range s.rules {
+		if rule.matches(c) {
+			return rule.Bucket
+		}
+	}
+	if c.Status == "True" {
+		return BucketHealthy
+	}
+	return BucketDegraded
+}
+
+// Summarize classifies every condition on every resource and computes each
+// resource's Readiness as the worst bucket among its gating conditions.
+func (s *ConditionSummarizer) Summarize(resources []ResourceConditions) []ResourceSummary {
+	summaries := make([]ResourceSummary, 0, len(resources))
+	for _, res := range resources {
+		readiness := BucketHealthy
+		var failing []FailingCondition
+
+		for _, c := range res.Conditions {
+			bucket := s.Classify(c)
+			if bucket != BucketHealthy {
+				failing = append(failing, FailingCondition{
+					Type:    c.Type,
+					Reason:  c.Reason,
+					Message: c.Message,
+					Bucket:  bucket,
+				})
+			}
+			if s.gatingTypes[c.Type] && bucketSeverity[bucket] > bucketSeverity[readiness] {
+				readiness = bucket
+			}
+		}
+
+		sort.SliceStable(failing, func(i, j int) bool {
+			return bucketSeverity[failing[i].Bucket] > bucketSeverity[failing[j].Bucket]
+		})
+
+		summaries = append(summaries, ResourceSummary{
+			Cluster:            res.Kind + "/" + res.Name,
+			Readiness:          readiness,
+			FailingConditions:  failing,
+			SuspectedRootCause: suspectedRootCause(failing),
+		})
+	}
+	return summaries
+}
+
+// suspectedRootCause picks the most urgent failing condition's message as a
+// first guess at what's wrong -- a starting point for the LLM to confirm or
+// correct, not a replacement for its analysis.
+func suspectedRootCause(failing []FailingCondition) string {
+	if len(failing) == 0 {
+		return ""
+	}
+	worst := failing[0]
+	if worst.Message != "" {
+		return fmt.Sprintf("%s: %s", worst.Type, worst.Message)
+	}
+	return fmt.Sprintf("%s: %s", worst.Type, worst.Reason)
+}
+
+// RenderJSON marshals summaries into the indented JSON blob injected into
+// the LLM prompt.
+func RenderJSON(summaries []ResourceSummary) (string, error) {
+	b, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal condition summary: %w", err)
+	}
+	return string(b), nil
+}
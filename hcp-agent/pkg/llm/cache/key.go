@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// volatileFields are stripped from any JSON object before hashing: they
+// change on every read without reflecting a real change in cluster state
+// (resourceVersion, managedFields), or reset on every kubelet heartbeat
+// (lastHeartbeatTime) -- left in, no two reads of an otherwise-unchanged
+// resource would ever hash the same.
+var volatileFields = map[string]bool{
+	"managedFields":     true,
+	"resourceVersion":   true,
+	"lastHeartbeatTime": true,
+}
+
+// normalize strips volatileFields and canonicalizes condition ordering in
+// data if it parses as JSON (the structured condition summary from
+// pkg/llm/analyzer does). k8sData that isn't JSON -- e.g. the legacy
+// rendered-text resource dump -- is returned unchanged, since there's no
+// structure to strip or reorder.
+func normalize(data string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return data
+	}
+
+	out, err := json.Marshal(stripAndSort(v))
+	if err != nil {
+		return data
+	}
+	return string(out)
+}
+
+func stripAndSort(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			if volatileFields[k] {
+				continue
+			}
+			out[k] = stripAndSort(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			out[i] = stripAndSort(child)
+		}
+		if isConditionList(out) {
+			sort.Slice(out, func(i, j int) bool {
+				return conditionType(out[i]) < conditionType(out[j])
+			})
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// isConditionList reports whether items looks like a Kubernetes-style
+// conditions slice -- every element an object with a "type" field -- the
+// only array shape whose order is safe to canonicalize; any other slice's
+// order may be meaningful (e.g. log lines) and is left alone.
+func isConditionList(items []interface{}) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["type"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func conditionType(item interface{}) string {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	t, _ := m["type"].(string)
+	return t
+}
+
+// writeField writes a length-prefixed record for s to h, so concatenating
+// fields without a delimiter can never make two logically different tuples
+// hash the same -- e.g. options{"a":"bc"} vs. options{"ab":"c"}, or a
+// k8sData/question byte boundary shift.
+func writeField(h io.Writer, s string) {
+	fmt.Fprintf(h, "%d:%s", len(s), s)
+}
+
+// Key returns a content-addressed cache key for an Analyze call, hashing
+// the model, its options, the normalized k8s data, and the question -- a
+// change to any of them must be a cache miss. Every field is written
+// length-prefixed (see writeField) so concatenation alone can't collide two
+// different tuples onto the same hash.
+func Key(model string, options map[string]string, k8sData, question string) string {
+	optKeys := make([]string, 0, len(options))
+	for k := range options {
+		optKeys = append(optKeys, k)
+	}
+	sort.Strings(optKeys)
+
+	h := sha256.New()
+	writeField(h, model)
+	for _, k := range optKeys {
+		writeField(h, k)
+		writeField(h, options[k])
+	}
+	writeField(h, normalize(k8sData))
+	writeField(h, question)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// entry is what's persisted to disk per cache key.
+type entry struct {
+	Response string    `json:"response"`
+	StoredAt time.Time `json:"storedAt"`
+}
+
+// FileStore is a content-addressed, TTL-expiring disk cache: one JSON file
+// per key under Dir. A real KV store (BoltDB) would avoid the
+// one-file-per-entry overhead, but this repo has no module manifest to pin
+// a new dependency to -- a directory of small JSON files is good enough for
+// the iterative-debugging use case this exists for.
+type FileStore struct {
+	Dir string
+	TTL time.Duration
+}
+
+// NewFileStore creates a FileStore rooted at dir. A TTL of zero means
+// entries never expire.
+func NewFileStore(dir string, ttl time.Duration) *FileStore {
+	return &FileStore{Dir: dir, TTL: ttl}
+}
+
+// Get returns the cached response for key, if present and not expired.
+func (s *FileStore) Get(key string) (string, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return "", false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return "", false
+	}
+
+	if s.TTL > 0 && time.Since(e.StoredAt) > s.TTL {
+		return "", false
+	}
+	return e.Response, true
+}
+
+// Set persists response under key.
+func (s *FileStore) Set(key, response string) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache dir %s: %w", s.Dir, err)
+	}
+
+	data, err := json.Marshal(entry{Response: response, StoredAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	if err := os.WriteFile(s.path(key), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache entry %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.Dir, key+".json")
+}
@@ -2,12 +2,17 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+var log = ctrl.Log.WithName("llm")
+
 // OllamaClient represents a client for the Ollama API
 type OllamaClient struct {
 	baseURL    string
@@ -39,21 +44,29 @@ type Options struct {
 	MaxTokens   int     `json:"max_tokens,omitempty"`
 }
 
-// Response represents the response from the Ollama API
+// Response represents one newline-delimited JSON chunk Ollama emits from
+// /api/generate. Done is only true on the final chunk of a streamed
+// response.
 type Response struct {
 	Model     string `json:"model"`
 	Response  string `json:"response"`
 	CreatedAt string `json:"created_at"`
+	Done      bool   `json:"done,omitempty"`
 }
 
-// Analyze sends the Kubernetes data to the LLM for analysis
-func (c *OllamaClient) Analyze(k8sData, question string) (string, error) {
-	prompt := fmt.Sprintf(`You are a Kubernetes expert specializing in OpenShift HyperShift. Analyze the following HyperShift resources and answer the question.
+// buildPrompt renders the "single-cluster deep dive" prompt -- including the
+// HostedCluster/NodePool condition dictionaries every adapter's Analyze
+// relies on to interpret conditions correctly -- for k8sData and question.
+// It lives here rather than per-adapter so every backend (ollama, openai,
+// anthropic, ...) sees the exact same instructions. See buildFleetPrompt for
+// the "fleet summary" mode, which is Ollama-only for now.
+func buildPrompt(k8sData, question string) string {
+	return fmt.Sprintf(`You are a Kubernetes expert specializing in OpenShift HyperShift. Analyze the following HyperShift resources and answer the question.
 
 HyperShift Resources:
 %s
 
-Provide a detailed analysis the conditions passed for each resource above. 
+Provide a detailed analysis the conditions passed for each resource above.
 For each resource use a bullet point title HostedCluster followed by the name.
 To do analyse the passed conditions you can use the following dictionaries of the HostedCluster and NodePool conditions so you can use to interpret the ones passed to you:
 
@@ -428,9 +441,51 @@ const (
 )
 
 At the end provide a summary including the overall health of the fleet of HostedClusters and NodePools.
-`, k8sData)
 
-	fmt.Println("Prompt: ", prompt)
+QUESTION: %s
+`, k8sData, question)
+}
+
+// buildFleetPrompt renders the "fleet summary" prompt: rather than a
+// single-cluster deep dive over one resource's raw conditions, it asks the
+// LLM to reason about fleetJSON, an already-aggregated fleet.Rollup (see
+// pkg/fleet) -- readiness counts, the most common failing conditions,
+// t-shirt-size distribution, and how many resources are mid-upgrade --
+// across every HostedCluster/NodePool it was computed from.
+func buildFleetPrompt(fleetJSON, question string) string {
+	return fmt.Sprintf(`You are a Kubernetes expert specializing in OpenShift HyperShift fleet operations. You are given a fleet-wide health rollup already aggregated across many HostedClusters and NodePools, not any single cluster's raw conditions.
+
+Fleet Rollup:
+%s
+
+Summarize the fleet's overall health, call out any readiness bucket or failing condition that stands out, and note anything unusual about the t-shirt-size distribution or how many resources are mid-upgrade.
+
+QUESTION: %s
+`, fleetJSON, question)
+}
+
+// Analyze sends the Kubernetes data to the LLM for analysis using the
+// "single-cluster deep dive" prompt mode.
+func (c *OllamaClient) Analyze(ctx context.Context, k8sData, question string) (string, error) {
+	prompt := buildPrompt(k8sData, question)
+	log.V(1).Info("Sending prompt to Ollama", "prompt", prompt)
+	return c.generate(ctx, prompt)
+}
+
+// AnalyzeFleet sends an already-aggregated fleet.Rollup (see pkg/fleet) to
+// the LLM using the "fleet summary" prompt mode, for reasoning about
+// overall fleet health instead of one HostedCluster/NodePool's raw
+// conditions.
+func (c *OllamaClient) AnalyzeFleet(ctx context.Context, fleetJSON, question string) (string, error) {
+	prompt := buildFleetPrompt(fleetJSON, question)
+	log.V(1).Info("Sending fleet summary prompt to Ollama", "prompt", prompt)
+	return c.generate(ctx, prompt)
+}
+
+// generate posts prompt to Ollama's /api/generate with streaming disabled
+// and returns the full response text. It's shared by Analyze and
+// AnalyzeFleet, which differ only in how the prompt is built.
+func (c *OllamaClient) generate(ctx context.Context, prompt string) (string, error) {
 	reqBody := Request{
 		Model:  c.model,
 		Prompt: prompt,
@@ -446,7 +501,13 @@ At the end provide a summary including the overall health of the fleet of Hosted
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(c.baseURL+"/api/generate", "application/json", bytes.NewBuffer(reqJSON))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/generate", bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request to Ollama: %w", err)
 	}
@@ -464,3 +525,70 @@ At the end provide a summary including the overall health of the fleet of Hosted
 
 	return response.Response, nil
 }
+
+// AnalyzeStream behaves like Analyze, but streams Ollama's NDJSON response
+// over the returned channel as it arrives instead of waiting for the full
+// answer. The channel is closed once generation completes; a final Chunk
+// with Err set is sent if the stream fails partway through.
+func (c *OllamaClient) AnalyzeStream(ctx context.Context, k8sData, question string) (<-chan Chunk, error) {
+	prompt := buildPrompt(k8sData, question)
+
+	log.V(1).Info("Streaming prompt to Ollama", "prompt", prompt)
+	reqBody := Request{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: true,
+		Options: Options{
+			Temperature: 0.7,
+			MaxTokens:   2000,
+		},
+	}
+
+	reqJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/generate", bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var piece Response
+			if err := decoder.Decode(&piece); err != nil {
+				if err != io.EOF {
+					ch <- Chunk{Err: fmt.Errorf("failed to decode streamed response: %w", err)}
+				}
+				return
+			}
+
+			if piece.Response != "" {
+				ch <- Chunk{Text: piece.Response}
+			}
+			if piece.Done {
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,144 @@
+// Package rootcause walks a resource's conditions *before* the LLM sees
+// them and produces a deterministic, ranked list of likely root causes from
+// known bubble-up relationships between conditions -- e.g. an etcd problem
+// surfacing as both KubeAPIServerAvailable=False and EtcdAvailable=False on
+// the same HostedCluster. The result is returned as structured data a
+// caller can use on its own, and is also rendered as a prompt section fed
+// to the LLM alongside it.
+package rootcause
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+)
+
+// Hint is one candidate root cause identified for a resource.
+type Hint struct {
+	Resource string // e.g. "HostedCluster/my-cluster"
+	Cause    string // short label, e.g. "cloud-provider infrastructure"
+	// Confidence ranks hints against each other for the same resource; it is
+	// not a calibrated probability.
+	Confidence  float64
+	Explanation string
+}
+
+// rule matches a combination of conditions on a single resource of kind
+// Kind and, if every condition in Requires is present with the given
+// status, reports Cause with Confidence.
+type rule struct {
+	kind       string
+	requires   map[string]string // condition type -> required status
+	cause      string
+	confidence float64
+	explain    string
+}
+
+// rules encodes the bubble-up relationships documented alongside the
+// HostedCluster/NodePool condition dictionaries in buildPrompt. Order
+// doesn't affect matching (every rule for a resource's kind is evaluated),
+// only the final ranking, which sorts by confidence.
+var rules = []rule{
+	{
+		kind:       "HostedCluster",
+		requires:   map[string]string{"Available": "False", "InfrastructureReady": "False"},
+		cause:      "cloud-provider infrastructure",
+		confidence: 0.8,
+		explain:    "Available=False with InfrastructureReady=False points at the cloud-provider layer (e.g. load balancers, quotas).",
+	},
+	{
+		kind:       "HostedCluster",
+		requires:   map[string]string{"KubeAPIServerAvailable": "False", "EtcdAvailable": "False"},
+		cause:      "etcd",
+		confidence: 0.85,
+		explain:    "KubeAPIServerAvailable=False with EtcdAvailable=False points at etcd rather than the API server itself.",
+	},
+	{
+		kind:       "HostedCluster",
+		requires:   map[string]string{"ValidReleaseImage": "False"},
+		cause:      "user-input: invalid release image",
+		confidence: 0.9,
+		explain:    "ValidReleaseImage=False is a user-input problem -- the requested release isn't valid for this HostedCluster.",
+	},
+	{
+		kind:       "HostedCluster",
+		requires:   map[string]string{"ValidConfiguration": "False"},
+		cause:      "user-input: invalid configuration",
+		confidence: 0.9,
+		explain:    "ValidConfiguration=False is a user-input problem -- the HostedCluster spec itself is invalid or unsupported.",
+	},
+	{
+		kind:       "NodePool",
+		requires:   map[string]string{"Ready": "False", "AllMachinesReady": "False"},
+		cause:      "CAPI/cloud quota",
+		confidence: 0.75,
+		explain:    "Ready=False with AllMachinesReady=False points at CAPI or a cloud-provider quota blocking machine creation.",
+	},
+	{
+		kind:       "NodePool",
+		requires:   map[string]string{"AllNodesHealthy": "False", "ReachedIgnitionEndpoint": "False"},
+		cause:      "ignition/security-group networking",
+		confidence: 0.75,
+		explain:    "AllNodesHealthy=False with ReachedIgnitionEndpoint=False points at ignition or security-group networking reachability (the OCPBUGS-11763-style NotReady-after-upgrade pattern).",
+	},
+}
+
+// Generate walks resources' conditions and returns the candidate root
+// causes matched against them, ranked from most to least confident.
+func Generate(resources []analyzer.ResourceConditions) []Hint {
+	var hints []Hint
+
+	for _, res := range resources {
+		byType := make(map[string]string, len(res.Conditions))
+		for _, c := range res.Conditions {
+			byType[c.Type] = c.Status
+		}
+
+		resourceName := res.Kind + "/" + res.Name
+		for _, r := range rules {
+			if r.kind != res.Kind {
+				continue
+			}
+			if !matches(byType, r.requires) {
+				continue
+			}
+			hints = append(hints, Hint{
+				Resource:    resourceName,
+				Cause:       r.cause,
+				Confidence:  r.confidence,
+				Explanation: r.explain,
+			})
+		}
+	}
+
+	sort.SliceStable(hints, func(i, j int) bool {
+		return hints[i].Confidence > hints[j].Confidence
+	})
+	return hints
+}
+
+func matches(byType map[string]string, requires map[string]string) bool {
+	for condType, wantStatus := range requires {
+		if byType[condType] != wantStatus {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderPromptSection renders hints as the "candidate root causes" section
+// appended to the LLM prompt, ordered the same way Generate ranked them.
+func RenderPromptSection(hints []Hint) string {
+	if len(hints) == 0 {
+		return "Candidate root causes (deterministic, pre-LLM): none identified.\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("Candidate root causes (deterministic, pre-LLM), most likely first:\n")
+	for _, h := range hints {
+		fmt.Fprintf(&b, "- %s: %s (confidence %.2f) -- %s\n", h.Resource, h.Cause, h.Confidence, h.Explanation)
+	}
+	return b.String()
+}
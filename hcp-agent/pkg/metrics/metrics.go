@@ -0,0 +1,89 @@
+// Package metrics registers the Prometheus collectors the agent exposes on
+// the controller-runtime Manager's built-in /metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// ReconcileDuration tracks how long a single Reconcile loop takes,
+	// including the LLM call.
+	ReconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "hcp_agent_reconcile_duration_seconds",
+		Help:    "Time spent running a single Reconcile loop.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// LLMCallDuration tracks the latency of a single Analyzer.Analyze call.
+	LLMCallDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "hcp_agent_llm_call_duration_seconds",
+		Help:    "Latency of a single LLM analysis call.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// LLMCallErrorsTotal counts Analyze calls that returned an error.
+	LLMCallErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hcp_agent_llm_call_errors_total",
+		Help: "Number of LLM analysis calls that returned an error.",
+	})
+
+	// LLMTokensEstimatedTotal accumulates a rough token count across all
+	// prompts sent to the LLM, estimated at ~4 characters per token.
+	LLMTokensEstimatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hcp_agent_llm_tokens_estimated_total",
+		Help: "Estimated number of tokens sent to the LLM across all analysis calls.",
+	})
+
+	// HostedClusterConditionStatus reports the status of each HostedCluster
+	// and NodePool condition as 1 (True), 0 (False) or -1 (Unknown/other).
+	HostedClusterConditionStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hcp_agent_condition_status",
+		Help: "Status of a HostedCluster/NodePool condition: 1=True, 0=False, -1=Unknown.",
+	}, []string{"namespace", "name", "kind", "type"})
+
+	// LLMCacheHitsTotal and LLMCacheMissesTotal count Analyze calls served
+	// from pkg/llm/cache's on-disk cache versus forwarded to the backend.
+	LLMCacheHitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hcp_agent_llm_cache_hits_total",
+		Help: "Number of LLM analysis calls served from the response cache.",
+	})
+	LLMCacheMissesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hcp_agent_llm_cache_misses_total",
+		Help: "Number of LLM analysis calls not found in the response cache.",
+	})
+
+	// LLMCostUSDTotal accumulates estimated spend across all cache-missed
+	// analysis calls, for backends configured with a per-1K-token cost.
+	LLMCostUSDTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hcp_agent_llm_cost_usd_total",
+		Help: "Estimated USD spend across all LLM analysis calls, for backends with a configured cost per 1K tokens.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ReconcileDuration,
+		LLMCallDuration,
+		LLMCallErrorsTotal,
+		LLMTokensEstimatedTotal,
+		HostedClusterConditionStatus,
+		LLMCacheHitsTotal,
+		LLMCacheMissesTotal,
+		LLMCostUSDTotal,
+	)
+}
+
+// ConditionStatusValue maps a Kubernetes condition status string to the
+// numeric value HostedClusterConditionStatus expects.
+func ConditionStatusValue(status string) float64 {
+	switch status {
+	case "True":
+		return 1
+	case "False":
+		return 0
+	default:
+		return -1
+	}
+}
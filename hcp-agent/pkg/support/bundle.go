@@ -0,0 +1,264 @@
+// Package support collects a HostedCluster/NodePool resource dump, operator
+// and control-plane pod logs, and an LLM analysis of them into a single
+// self-contained bundle a user can attach to a bug report -- and later feed
+// back through --offline/--rca-path to reproduce the analysis.
+package support
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm"
+)
+
+// ResourceProvider is the subset of k8s.Client (or k8s.LocalClient) needed
+// to dump HostedCluster/NodePool state into a bundle.
+type ResourceProvider interface {
+	GetAllResources(namespace string) (string, error)
+	// GetConditionSummary returns the compact JSON readiness rollup (see
+	// pkg/llm/analyzer) fed to the LLM in place of the raw resource dump.
+	GetConditionSummary(namespace string) (string, error)
+	// GetRootCauseHints returns the ranked, deterministic root-cause
+	// candidates (see pkg/llm/rootcause) computed before the LLM runs.
+	GetRootCauseHints(namespace string) (string, error)
+}
+
+// PodLogProvider is implemented by the live k8s.Client to discover and fetch
+// operator and control-plane pod logs. It has no offline counterpart: a
+// bundle is produced from a live cluster, then consumed offline elsewhere.
+type PodLogProvider interface {
+	ListPodNames(namespace, labelSelector string) ([]string, error)
+	GetPodLogs(namespace, podName, containerName string, tailLines int64) (string, error)
+}
+
+// PodLogSpec names a namespace/label-selector pair to collect pod logs
+// from, e.g. the hypershift-operator namespace or a HostedCluster's
+// control-plane namespace.
+type PodLogSpec struct {
+	Namespace     string
+	LabelSelector string
+}
+
+// Bundle is the in-memory result of a collection pass.
+type Bundle struct {
+	Resources      string
+	PodLogs        map[string]string // keyed by "namespace/pod"
+	RootCauseHints string
+	Analysis       string
+}
+
+// Collect gathers the resource dump, the pod logs named by podLogSpecs, and
+// the resulting LLM analysis concurrently via an errgroup, so a slow log
+// stream doesn't hold up the others.
+func Collect(ctx context.Context, resources ResourceProvider, pods PodLogProvider, analyzer llm.Analyzer, namespace, analysisQuestion string, podLogSpecs []PodLogSpec) (*Bundle, error) {
+	bundle := &Bundle{PodLogs: make(map[string]string)}
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() error {
+		data, err := resources.GetAllResources(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to collect resources: %w", err)
+		}
+		bundle.Resources = data
+
+		// The LLM gets the structured condition summary rather than the raw
+		// dump above: resources.txt stays around for a human to read in full,
+		// but the prompt only needs the classified, already-rolled-up view.
+		summary, err := resources.GetConditionSummary(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to summarize conditions: %w", err)
+		}
+
+		// Stored separately from the LLM analysis, so rootcause.txt carries
+		// the rules-based result on its own. Collect still returns only after
+		// the LLM call below, and an analysis error fails the whole bundle.
+		hints, err := resources.GetRootCauseHints(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to generate root-cause hints: %w", err)
+		}
+		bundle.RootCauseHints = hints
+
+		analysis, err := analyzer.Analyze(ctx, summary+"\n"+hints, analysisQuestion)
+		if err != nil {
+			return fmt.Errorf("failed to analyze resources: %w", err)
+		}
+		bundle.Analysis = analysis
+		return nil
+	})
+
+	var mu sync.Mutex
+	for _, spec := range podLogSpecs {
+		spec := spec
+		group.Go(func() error {
+			names, err := pods.ListPodNames(spec.Namespace, spec.LabelSelector)
+			if err != nil {
+				return fmt.Errorf("failed to list pods in %s: %w", spec.Namespace, err)
+			}
+
+			for _, name := range names {
+				logs, err := pods.GetPodLogs(spec.Namespace, name, "", 1000)
+				if err != nil {
+					return fmt.Errorf("failed to get logs for %s/%s: %w", spec.Namespace, name, err)
+				}
+				mu.Lock()
+				bundle.PodLogs[spec.Namespace+"/"+name] = logs
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// WriteZip writes bundle as a zip archive: resources.txt, rootcause.txt,
+// analysis.txt, and one logs/<namespace>_<pod>.log file per collected pod.
+func WriteZip(w io.Writer, bundle *Bundle) error {
+	zw := zip.NewWriter(w)
+
+	if err := writeZipFile(zw, "resources.txt", bundle.Resources); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "rootcause.txt", bundle.RootCauseHints); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "analysis.txt", bundle.Analysis); err != nil {
+		return err
+	}
+	for key, logs := range bundle.PodLogs {
+		name := "logs/" + strings.ReplaceAll(key, "/", "_") + ".log"
+		if err := writeZipFile(zw, name, logs); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name, content string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s in bundle: %w", name, err)
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// EventsProvider supplies the bundle's events.json artifact -- e.g. a
+// watch.Watcher's recently observed condition transitions -- for
+// CollectAndStream to fold in alongside resources/logs/analysis.
+type EventsProvider func() (string, error)
+
+// CollectAndStream behaves like Collect followed by WriteZip, except each
+// artifact is written into the zip as soon as its own collector finishes
+// instead of only after every collector is done -- so api.Server's
+// /api/support-bundle handler can stream the archive to the client as it's
+// assembled rather than buffering the whole thing in memory first. events,
+// if non-nil, runs concurrently with everything else and is written as
+// events.json.
+func CollectAndStream(ctx context.Context, w io.Writer, resources ResourceProvider, pods PodLogProvider, analyzer llm.Analyzer, namespace, analysisQuestion string, podLogSpecs []PodLogSpec, events EventsProvider) error {
+	zw := zip.NewWriter(w)
+
+	var zmu sync.Mutex
+	flusher, _ := w.(http.Flusher)
+	writeEntry := func(name, content string) error {
+		zmu.Lock()
+		defer zmu.Unlock()
+		if err := writeZipFile(zw, name, content); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	group, gctx := errgroup.WithContext(ctx)
+
+	group.Go(func() error {
+		data, err := resources.GetAllResources(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to collect resources: %w", err)
+		}
+		return writeEntry("resources.txt", data)
+	})
+
+	group.Go(func() error {
+		// The LLM gets the structured condition summary rather than the raw
+		// dump above: resources.txt stays around for a human to read in
+		// full, but the prompt only needs the classified, already-rolled-up
+		// view.
+		summary, err := resources.GetConditionSummary(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to summarize conditions: %w", err)
+		}
+
+		// Computed and written independently of the LLM call below, so a
+		// client reading the stream as it arrives sees rootcause.txt without
+		// waiting on (or paying for) an LLM analysis at all.
+		hints, err := resources.GetRootCauseHints(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to generate root-cause hints: %w", err)
+		}
+		if err := writeEntry("rootcause.txt", hints); err != nil {
+			return err
+		}
+
+		analysis, err := analyzer.Analyze(gctx, summary+"\n"+hints, analysisQuestion)
+		if err != nil {
+			return fmt.Errorf("failed to analyze resources: %w", err)
+		}
+		return writeEntry("analysis.txt", analysis)
+	})
+
+	for _, spec := range podLogSpecs {
+		spec := spec
+		group.Go(func() error {
+			names, err := pods.ListPodNames(spec.Namespace, spec.LabelSelector)
+			if err != nil {
+				return fmt.Errorf("failed to list pods in %s: %w", spec.Namespace, err)
+			}
+
+			for _, name := range names {
+				logs, err := pods.GetPodLogs(spec.Namespace, name, "", 1000)
+				if err != nil {
+					return fmt.Errorf("failed to get logs for %s/%s: %w", spec.Namespace, name, err)
+				}
+				entryName := "logs/" + strings.ReplaceAll(spec.Namespace+"/"+name, "/", "_") + ".log"
+				if err := writeEntry(entryName, logs); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	if events != nil {
+		group.Go(func() error {
+			data, err := events()
+			if err != nil {
+				return fmt.Errorf("failed to collect events: %w", err)
+			}
+			return writeEntry("events.json", data)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
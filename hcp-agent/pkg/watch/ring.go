@@ -0,0 +1,50 @@
+package watch
+
+import "github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+
+// conditionState is one observed state of a single condition type on a
+// resource, tagged with the lastTransitionTime Kubernetes conventions use to
+// tell a real Type/Status/Reason change apart from a no-op status update
+// that left the condition itself untouched.
+type conditionState struct {
+	analyzer.Condition
+	LastTransitionTime string
+}
+
+// ring is a fixed-capacity, oldest-evicted-first history of one condition
+// type's observed states on one resource, so a transition's prompt can
+// include the last N states instead of just the current and previous one --
+// enough to tell a flapping condition from a single persistent failure.
+type ring struct {
+	states   []conditionState
+	capacity int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{capacity: capacity}
+}
+
+// push appends state, evicting the oldest entry once capacity is reached.
+func (r *ring) push(state conditionState) {
+	r.states = append(r.states, state)
+	if len(r.states) > r.capacity {
+		r.states = r.states[len(r.states)-r.capacity:]
+	}
+}
+
+// last returns the most recently pushed state, if any.
+func (r *ring) last() (conditionState, bool) {
+	if len(r.states) == 0 {
+		return conditionState{}, false
+	}
+	return r.states[len(r.states)-1], true
+}
+
+// history returns the retained states, oldest first.
+func (r *ring) history() []analyzer.Condition {
+	out := make([]analyzer.Condition, len(r.states))
+	for i, s := range r.states {
+		out[i] = s.Condition
+	}
+	return out
+}
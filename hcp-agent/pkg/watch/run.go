@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+)
+
+// AnalyzeFunc matches llm.Analyzer.Analyze's signature, so an
+// *llm.OllamaClient (or any other adapter) can be passed directly.
+type AnalyzeFunc func(ctx context.Context, k8sData, question string) (string, error)
+
+const transitionQuestion = "Explain why this condition transitioned, and whether the history shows flapping or a persistent failure."
+
+// Run reads TransitionEvents off events and, for every transition whose
+// NewBucket is Degraded or UserActionRequired, asks analyze to explain it --
+// fed History so the LLM can tell flapping from a persistent failure -- then
+// fans the explanation out to every sink. It blocks until ctx is canceled or
+// events is closed.
+func Run(ctx context.Context, events <-chan TransitionEvent, analyze AnalyzeFunc, sinks []Sink, log logr.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.NewBucket != analyzer.BucketDegraded && event.NewBucket != analyzer.BucketUserActionRequired {
+				continue
+			}
+
+			explanation, err := analyze(ctx, renderTransitionPrompt(event), transitionQuestion)
+			if err != nil {
+				log.Error(err, "failed to analyze transition", "resource", event.Kind+"/"+event.Name, "condition", event.Condition.Type)
+				continue
+			}
+
+			for _, sink := range sinks {
+				if err := sink.Send(ctx, event, explanation); err != nil {
+					log.Error(err, "failed to send transition to sink")
+				}
+			}
+		}
+	}
+}
+
+// renderTransitionPrompt describes event's resource, the condition that
+// transitioned, and its retained history as the k8sData input to analyze.
+func renderTransitionPrompt(event TransitionEvent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Resource: %s/%s\n", event.Kind, event.Name)
+	if event.Namespace != "" {
+		fmt.Fprintf(&b, "Namespace: %s\n", event.Namespace)
+	}
+	fmt.Fprintf(&b, "Condition: %s\n", event.Condition.Type)
+	if event.PreviousBucket != nil {
+		fmt.Fprintf(&b, "Previous readiness bucket: %s\n", *event.PreviousBucket)
+	}
+	fmt.Fprintf(&b, "New readiness bucket: %s\n\n", event.NewBucket)
+
+	fmt.Fprintf(&b, "History (oldest first, %d states):\n", len(event.History))
+	for _, c := range event.History {
+		fmt.Fprintf(&b, "- status=%s reason=%s message=%s\n", c.Status, c.Reason, c.Message)
+	}
+
+	return b.String()
+}
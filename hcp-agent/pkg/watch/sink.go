@@ -0,0 +1,133 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Sink delivers an explained TransitionEvent somewhere a human can see it.
+type Sink interface {
+	Send(ctx context.Context, event TransitionEvent, explanation string) error
+}
+
+// StdoutSink prints transitions to stdout, for local runs and debugging.
+type StdoutSink struct{}
+
+func (StdoutSink) Send(_ context.Context, event TransitionEvent, explanation string) error {
+	fmt.Printf("[%s] %s/%s: %s -> %s (%s)\n%s\n", event.NewBucket, event.Kind, event.Name,
+		event.Condition.Type, event.Condition.Status, event.Condition.Reason, explanation)
+	return nil
+}
+
+// WebhookSink POSTs a JSON payload describing the transition and its
+// explanation to a generic webhook URL.
+type WebhookSink struct {
+	URL        string
+	httpClient *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, httpClient: &http.Client{}}
+}
+
+type webhookPayload struct {
+	Kind        string            `json:"kind"`
+	Namespace   string            `json:"namespace"`
+	Name        string            `json:"name"`
+	Condition   analyzerCondition `json:"condition"`
+	NewBucket   string            `json:"newBucket"`
+	Explanation string            `json:"explanation"`
+}
+
+// analyzerCondition mirrors analyzer.Condition for JSON encoding without
+// importing field tags into that package, which has no other need for them.
+type analyzerCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (s *WebhookSink) Send(ctx context.Context, event TransitionEvent, explanation string) error {
+	payload := webhookPayload{
+		Kind:      event.Kind,
+		Namespace: event.Namespace,
+		Name:      event.Name,
+		Condition: analyzerCondition{
+			Type:    event.Condition.Type,
+			Status:  event.Condition.Status,
+			Reason:  event.Condition.Reason,
+			Message: event.Condition.Message,
+		},
+		NewBucket:   string(event.NewBucket),
+		Explanation: explanation,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transition payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build transition webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post transition webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("transition webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SlackSink posts a transition and its explanation to a Slack incoming
+// webhook URL.
+type SlackSink struct {
+	WebhookURL string
+	httpClient *http.Client
+}
+
+func NewSlackSink(webhookURL string) *SlackSink {
+	return &SlackSink{WebhookURL: webhookURL, httpClient: &http.Client{}}
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+func (s *SlackSink) Send(ctx context.Context, event TransitionEvent, explanation string) error {
+	text := fmt.Sprintf("*%s* %s/%s: `%s` -> `%s` (%s)\n%s",
+		event.NewBucket, event.Kind, event.Name, event.Condition.Type, event.Condition.Status,
+		event.Condition.Reason, explanation)
+
+	body, err := json.Marshal(slackMessage{Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Package watch detects HostedCluster/NodePool condition transitions as they
+// happen, independent of controller.Reconciler's reconcile-loop diffing, and
+// emits them on a channel for anything that wants to react in real time --
+// e.g. the Run loop in this package that explains Degraded/UserActionRequired
+// transitions and fans them out to sinks (stdout, a webhook, Slack).
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/yourusername/k8s-llm-analyzer/pkg/llm/analyzer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// defaultHistorySize is how many past states of a condition are kept per
+// resource/condition-type -- enough to tell a flapping condition (several
+// transitions within the window) from a single persistent failure.
+const defaultHistorySize = 5
+
+// defaultRecentEventsCap bounds how many emitted TransitionEvents
+// RecentEvents retains across all resources, so a long-running agent's
+// support bundle gets a recent window rather than an ever-growing log.
+const defaultRecentEventsCap = 200
+
+// TransitionEvent is emitted whenever a condition's lastTransitionTime
+// advances on a watched resource.
+type TransitionEvent struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Condition analyzer.Condition
+	// PreviousBucket is nil if this is the first observation of this
+	// resource/condition-type pair.
+	PreviousBucket *analyzer.Bucket
+	NewBucket      analyzer.Bucket
+	// History is up to defaultHistorySize states of this same
+	// resource/condition-type pair, oldest first, ending with this one.
+	History []analyzer.Condition
+}
+
+// Watcher watches unstructured objects through a controller-runtime cache's
+// informers and emits a TransitionEvent on Events() whenever one of their
+// conditions' lastTransitionTime advances.
+type Watcher struct {
+	log         logr.Logger
+	summarizer  *analyzer.ConditionSummarizer
+	historySize int
+
+	events chan TransitionEvent
+
+	mu     sync.Mutex
+	rings  map[string]*ring // key: namespace/kind/name/conditionType
+	recent []TransitionEvent
+}
+
+// NewWatcher creates a Watcher. Call Start to register informers and begin
+// reading TransitionEvents off Events().
+func NewWatcher(log logr.Logger) *Watcher {
+	return &Watcher{
+		log:         log,
+		summarizer:  analyzer.NewConditionSummarizer(),
+		historySize: defaultHistorySize,
+		events:      make(chan TransitionEvent, 64),
+		rings:       make(map[string]*ring),
+	}
+}
+
+// Events returns the channel TransitionEvents are emitted on. It is never
+// closed by Watcher; stop reading once the ctx passed to Start is canceled.
+func (w *Watcher) Events() <-chan TransitionEvent {
+	return w.events
+}
+
+// RecentEvents returns up to defaultRecentEventsCap of the most recently
+// emitted TransitionEvents across every watched resource, oldest first --
+// unlike Events(), this is safe to call repeatedly without consuming
+// anything, e.g. from api.Server's /api/support-bundle handler to fold
+// recent condition transitions into a support bundle as events.json.
+func (w *Watcher) RecentEvents() []TransitionEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]TransitionEvent, len(w.recent))
+	copy(out, w.recent)
+	return out
+}
+
+// Start registers an event handler with c's informer for each kind in gvks
+// and blocks until ctx is canceled.
+func (w *Watcher) Start(ctx context.Context, c cache.Cache, gvks map[string]schema.GroupVersionKind) error {
+	for kind, gvk := range gvks {
+		kind := kind
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+
+		informer, err := c.GetInformer(ctx, obj)
+		if err != nil {
+			return fmt.Errorf("failed to get informer for %s: %w", kind, err)
+		}
+
+		if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { w.handle(kind, obj) },
+			UpdateFunc: func(_, obj interface{}) { w.handle(kind, obj) },
+		}); err != nil {
+			return fmt.Errorf("failed to add event handler for %s: %w", kind, err)
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (w *Watcher) handle(kind string, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	name := u.GetName()
+	namespace := u.GetNamespace()
+
+	raw, exists, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if !exists {
+		return
+	}
+
+	for _, c := range raw {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		condType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		lastTransitionTime, _, _ := unstructured.NestedString(condition, "lastTransitionTime")
+
+		w.observe(kind, namespace, name, analyzer.Condition{
+			Type:    condType,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		}, lastTransitionTime)
+	}
+}
+
+// observe records state for kind/namespace/name's cond.Type condition and
+// emits a TransitionEvent if lastTransitionTime advanced since the last
+// observation -- deduping the repeated status updates a controller can emit
+// without the condition itself actually changing.
+func (w *Watcher) observe(kind, namespace, name string, cond analyzer.Condition, lastTransitionTime string) {
+	key := fmt.Sprintf("%s/%s/%s/%s", namespace, kind, name, cond.Type)
+
+	w.mu.Lock()
+	r, ok := w.rings[key]
+	if !ok {
+		r = newRing(w.historySize)
+		w.rings[key] = r
+	}
+
+	prev, hadPrev := r.last()
+	if hadPrev && prev.LastTransitionTime == lastTransitionTime {
+		w.mu.Unlock()
+		return
+	}
+
+	r.push(conditionState{Condition: cond, LastTransitionTime: lastTransitionTime})
+	history := r.history()
+	w.mu.Unlock()
+
+	event := TransitionEvent{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Condition: cond,
+		NewBucket: w.summarizer.Classify(cond),
+		History:   history,
+	}
+	if hadPrev {
+		prevBucket := w.summarizer.Classify(prev.Condition)
+		event.PreviousBucket = &prevBucket
+	}
+
+	w.mu.Lock()
+	w.recent = append(w.recent, event)
+	if len(w.recent) > defaultRecentEventsCap {
+		w.recent = w.recent[len(w.recent)-defaultRecentEventsCap:]
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.events <- event:
+	default:
+		w.log.Info("dropping transition event, channel full", "key", key)
+	}
+}
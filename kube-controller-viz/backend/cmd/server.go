@@ -8,6 +8,7 @@ import (
 
 	"kube-controller-viz/pkg/api"
 	"kube-controller-viz/pkg/k8s"
+	"kube-controller-viz/pkg/logging"
 	"kube-controller-viz/pkg/parser"
 )
 
@@ -17,8 +18,15 @@ func main() {
 	useK8sAPI := flag.Bool("use-k8s-api", false, "Connect to Kubernetes API server")
 	kubeconfig := flag.String("kubeconfig", "", "Path to kubeconfig file (defaults to in-cluster config if empty)")
 	port := flag.Int("port", 8080, "Port to serve the API on")
+	lokiURL := flag.String("loki-url", "", "Loki push API URL (e.g. http://loki:3100/loki/api/v1/push) to additionally ship logs to. Leave empty to disable.")
 	flag.Parse()
 
+	var sink logging.Sink
+	if *lokiURL != "" {
+		sink = logging.NewLokiSink(*lokiURL, map[string]string{"app": "k8s-llm-analyzer", "component": "kube-controller-viz"})
+	}
+	logger := logging.NewLogger("kube-controller-viz", sink)
+
 	// Initialize the log parser if a log file is provided
 	var logParser *parser.LogParser
 	if *logFile != "" {
@@ -27,7 +35,7 @@ func main() {
 		if err != nil {
 			log.Fatalf("Failed to initialize log parser: %v", err)
 		}
-		log.Printf("Initialized log parser with file: %s", *logFile)
+		logger.Info("Initialized log parser", logging.Fields{"name": *logFile})
 	}
 
 	// Initialize Kubernetes client if requested
@@ -45,12 +53,12 @@ func main() {
 		if err != nil {
 			log.Fatalf("Failed to create Kubernetes client: %v", err)
 		}
-		log.Println("Connected to Kubernetes API server")
+		logger.Info("Connected to Kubernetes API server", nil)
 	}
 
 	// Start the API server
 	server := api.NewServer(logParser, k8sClient)
-	log.Printf("Starting API server on port %d...", *port)
+	logger.Info("Starting API server", logging.Fields{"port": *port})
 	if err := server.Start(*port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
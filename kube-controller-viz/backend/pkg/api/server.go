@@ -1,11 +1,16 @@
 package api
 
 import (
+	"archive/zip"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/sync/errgroup"
+
 	"kube-controller-viz/pkg/k8s"
+	"kube-controller-viz/pkg/metrics"
 	"kube-controller-viz/pkg/parser"
 )
 
@@ -35,6 +40,13 @@ func (s *Server) Start(port int) error {
 	// Multiple steps can belong to a single event, showing the reconciliation progress
 	http.HandleFunc("/api/steps", s.handleGetSteps)
 
+	// Support bundle zips state/events/steps (and, if --use-k8s-api was
+	// passed, pod logs) into a single downloadable archive for bug reports
+	http.HandleFunc("/api/support-bundle", s.handleGetSupportBundle)
+
+	// Prometheus metrics for the parsed controller state
+	http.Handle("/metrics", promhttp.Handler())
+
 	// Serve static files from the frontend directory
 	fs := http.FileServer(http.Dir("../frontend/build"))
 	http.Handle("/", fs)
@@ -56,6 +68,9 @@ func (s *Server) handleGetState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metrics.QueueLength.Set(float64(state.QueueLength))
+	metrics.ProcessingRate.Set(state.ProcessingRate)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(state)
 }
@@ -93,3 +108,94 @@ func (s *Server) handleGetSteps(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(stepsJSON))
 }
+
+// handleGetSupportBundle zips the parsed controller state, events and steps
+// JSON together, plus pod logs for an optional namespace/label-selector if a
+// Kubernetes client was configured, using an errgroup to collect them
+// concurrently and stream the archive back as it's built.
+func (s *Server) handleGetSupportBundle(w http.ResponseWriter, r *http.Request) {
+	if s.logParser == nil {
+		http.Error(w, "Log parser not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	type file struct {
+		name    string
+		content string
+	}
+
+	group := errgroup.Group{}
+	files := make([]file, 3)
+
+	group.Go(func() error {
+		state, err := s.logParser.GetControllerState()
+		if err != nil {
+			return fmt.Errorf("failed to get controller state: %w", err)
+		}
+		stateJSON, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal controller state: %w", err)
+		}
+		files[0] = file{"state.json", string(stateJSON)}
+		return nil
+	})
+	group.Go(func() error {
+		eventsJSON, err := s.logParser.GetEventsJSON()
+		if err != nil {
+			return fmt.Errorf("failed to get events: %w", err)
+		}
+		files[1] = file{"events.json", eventsJSON}
+		return nil
+	})
+	group.Go(func() error {
+		stepsJSON, err := s.logParser.GetStepsJSON()
+		if err != nil {
+			return fmt.Errorf("failed to get steps: %w", err)
+		}
+		files[2] = file{"steps.json", stepsJSON}
+		return nil
+	})
+
+	podNamespace := r.URL.Query().Get("pod-namespace")
+	podLabelSelector := r.URL.Query().Get("pod-label-selector")
+	var podLogFiles []file
+	if s.k8sClient != nil && podNamespace != "" {
+		group.Go(func() error {
+			podNames, err := s.k8sClient.GetControllerPods(podNamespace, podLabelSelector)
+			if err != nil {
+				return fmt.Errorf("failed to list pods in %s: %w", podNamespace, err)
+			}
+			for _, name := range podNames {
+				logs, err := s.k8sClient.GetPodLogs(podNamespace, name, "", 1000)
+				if err != nil {
+					return fmt.Errorf("failed to get logs for %s/%s: %w", podNamespace, name, err)
+				}
+				podLogFiles = append(podLogFiles, file{"logs/" + podNamespace + "_" + name + ".log", logs})
+			}
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to collect support bundle: %v", err), http.StatusInternalServerError)
+		return
+	}
+	files = append(files, podLogFiles...)
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=support-bundle.zip")
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		zf, err := zw.Create(f.name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write %s to bundle: %v", f.name, err), http.StatusInternalServerError)
+			return
+		}
+		if _, err := zf.Write([]byte(f.content)); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write %s to bundle: %v", f.name, err), http.StatusInternalServerError)
+			return
+		}
+	}
+	zw.Close()
+}
@@ -0,0 +1,24 @@
+// Package metrics registers the Prometheus collectors exposed on the API
+// server's /metrics endpoint.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// QueueLength mirrors ControllerState.QueueLength, the number of events
+	// seen in the parsed log.
+	QueueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kube_controller_viz_queue_length",
+		Help: "Number of controller events found in the parsed log.",
+	})
+
+	// ProcessingRate mirrors ControllerState.ProcessingRate.
+	ProcessingRate = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kube_controller_viz_processing_rate",
+		Help: "Events processed per second, derived from the parsed log.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(QueueLength, ProcessingRate)
+}